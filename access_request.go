@@ -0,0 +1,73 @@
+package go_policy_enforcer
+
+import "reflect"
+
+// Roots of the field paths used by rules evaluated against an AccessRequest.
+const (
+	subjectRoot     = "subject"
+	actionRoot      = "action"
+	resourceRoot    = "resource"
+	environmentRoot = "env"
+)
+
+// AccessRequest describes who is asking to do what to which resource, and under
+// which conditions, for attribute based access control. Pass an AccessRequest,
+// or a pointer to one, anywhere a resource is accepted, such as Enforce or
+// Decide, and rule field paths are rooted at one of:
+//   - "subject.", e.g. "subject.TenantID", for the Subject.
+//   - "action", for the Action.
+//   - "resource.", e.g. "resource.Owner.ID", for the Resource.
+//   - "env.", e.g. "env.ip", for the Environment.
+//
+// A FieldRef may refer to a different root than the rule's field, so a rule can
+// compare the subject with the resource:
+//
+//	Rule{Field: "subject.TenantID", Operator: "==", Value: FieldRef{Field: "resource.TenantID"}}
+//
+// The AccessRequest struct has the following fields:
+// - Subject: The user or service making the request, typically a struct.
+// - Action: The action being requested, e.g. "read".
+// - Resource: The resource the action applies to, typically a struct.
+// - Environment: Attributes of the request itself, such as the time or client
+// address, typically a map[string]any or a struct.
+type AccessRequest struct {
+	Subject     any
+	Action      string
+	Resource    any
+	Environment any
+}
+
+// root returns the value rule field paths are resolved against.
+func (r AccessRequest) root() reflect.Value {
+	return reflect.ValueOf(map[string]any{
+		subjectRoot:     r.Subject,
+		actionRoot:      r.Action,
+		resourceRoot:    r.Resource,
+		environmentRoot: r.Environment,
+	})
+}
+
+// resourceRootValue returns the value rule field paths are resolved against for
+// a resource, which is the resource itself, with pointers dereferenced, or the
+// roots of an AccessRequest. It returns false if the resource is neither a struct
+// nor an AccessRequest.
+func resourceRootValue(resource any) (reflect.Value, bool) {
+	switch r := resource.(type) {
+	case AccessRequest:
+		return r.root(), true
+	case *AccessRequest:
+		if r != nil {
+			return r.root(), true
+		}
+	}
+
+	v := reflect.ValueOf(resource)
+
+	// Handle pointers by dereferencing them
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	// Ensure we're working with a struct
+	return v, v.Kind() == reflect.Struct
+}
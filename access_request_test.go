@@ -0,0 +1,135 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"testing"
+)
+
+type accessRequestTestUser struct {
+	ID       int
+	TenantID string
+	Roles    []string
+}
+
+type accessRequestTestDocument struct {
+	OwnerID  int
+	TenantID string
+	Labels   map[string]string
+}
+
+func TestPolicyEnforcer_Enforce_AccessRequest(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name:   "SameTenant",
+			Target: []Rule{{Field: "action", Operator: "in", Value: []string{"read", "write"}}},
+			Rules: []Rule{
+				{Field: "subject.TenantID", Operator: "==", Value: FieldRef{Field: "resource.TenantID"}},
+				{Any: []Rule{
+					{Field: "action", Operator: "==", Value: "read"},
+					{Field: "resource.OwnerID", Operator: "==", Value: FieldRef{Field: "subject.ID"}},
+				}},
+			},
+			Effect: EffectAllow,
+		},
+		{
+			Name:   "OfficeHours",
+			Effect: EffectDeny,
+			Rules:  []Rule{{Field: "env.hour", Operator: ">=", Value: 22}},
+		},
+	}
+
+	alice := accessRequestTestUser{ID: 1, TenantID: "acme"}
+	bob := &accessRequestTestUser{ID: 2, TenantID: "globex"}
+	doc := accessRequestTestDocument{OwnerID: 1, TenantID: "acme"}
+
+	tests := []struct {
+		name     string
+		request  any
+		expected bool
+	}{
+		{"owner writes", AccessRequest{Subject: alice, Action: "write", Resource: doc, Environment: map[string]any{"hour": 10}}, true},
+		{"same tenant reads", AccessRequest{Subject: accessRequestTestUser{ID: 3, TenantID: "acme"}, Action: "read", Resource: doc, Environment: map[string]any{"hour": 10}}, true},
+		{"same tenant writes", AccessRequest{Subject: accessRequestTestUser{ID: 3, TenantID: "acme"}, Action: "write", Resource: doc, Environment: map[string]any{"hour": 10}}, false},
+		{"other tenant", AccessRequest{Subject: bob, Action: "read", Resource: &doc, Environment: map[string]any{"hour": 10}}, false},
+		{"pointer request", &AccessRequest{Subject: alice, Action: "read", Resource: doc, Environment: map[string]any{"hour": 10}}, true},
+		{"late at night", AccessRequest{Subject: alice, Action: "read", Resource: doc, Environment: map[string]any{"hour": 23}}, false},
+		{"unknown action", AccessRequest{Subject: alice, Action: "delete", Resource: doc, Environment: map[string]any{"hour": 10}}, false},
+	}
+
+	enforcer := NewPolicyEnforcer(policies)
+
+	program, err := Compile(*policies, AccessRequest{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := enforcer.EnforceE(tt.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ok != tt.expected {
+				t.Errorf("expected EnforceE to return %v, but got %v", tt.expected, ok)
+			}
+
+			if result := program.Enforce(tt.request); result != tt.expected {
+				t.Errorf("expected the compiled policies to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_AccessRequestPaths(t *testing.T) {
+	request := AccessRequest{
+		Subject:     accessRequestTestUser{ID: 1, TenantID: "acme", Roles: []string{"editor"}},
+		Action:      "read",
+		Resource:    accessRequestTestDocument{Labels: map[string]string{"team": "core"}},
+		Environment: struct{ Region string }{Region: "eu"},
+	}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected bool
+		err      error
+	}{
+		{"subject field", Rule{Field: "subject.TenantID", Operator: "==", Value: "acme"}, true, nil},
+		{"indexed subject field", Rule{Field: "subject.Roles[0]", Operator: "==", Value: "editor"}, true, nil},
+		{"resource map", Rule{Field: "resource.Labels.team", Operator: "==", Value: "core"}, true, nil},
+		{"action", Rule{Field: "action", Operator: "!=", Value: "write"}, true, nil},
+		{"struct environment", Rule{Field: "env.Region", Operator: "==", Value: "eu"}, true, nil},
+		{"unknown root", Rule{Field: "user.TenantID", Operator: "==", Value: "acme"}, false, ErrFieldNotFound},
+		{"missing field", Rule{Field: "subject.Email", Operator: "==", Value: "x"}, false, ErrFieldNotFound},
+		{"root is case sensitive", Rule{Field: "Subject.TenantID", Operator: "==", Value: "acme"}, false, ErrFieldNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "AccessPolicy", Rules: []Rule{tt.rule}}
+
+			ok, err := policy.EvaluateE(request)
+			if ok != tt.expected {
+				t.Errorf("expected EvaluateE to return %v, but got %v", tt.expected, ok)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_AccessRequestWithoutEnvironment(t *testing.T) {
+	policy := Policy{Name: "AccessPolicy", Rules: []Rule{{Field: "env.hour", Operator: "<", Value: 22}}}
+
+	if _, err := policy.EvaluateE(AccessRequest{Action: "read"}); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, but got %v", err)
+	}
+
+	var request *AccessRequest
+	if _, err := policy.EvaluateE(request); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch for a nil request, but got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package go_policy_enforcer
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// Tolerance is the value of an "approx_eq" rule: the number to compare with and
+// how far from it a value may be. A value passes when it is within any of the
+// tolerances that are set, so Tolerance{Value: 0.3, Absolute: 1e-9} passes for
+// 0.1 + 0.2.
+//
+// In JSON a Tolerance is written as an object such as
+// {"value": 0.3, "abs": 1e-9, "rel": 1e-12, "ulps": 4}.
+//
+// The Tolerance struct has the following fields:
+// - Value: The number to compare with.
+// - Absolute: The largest difference allowed, e.g. 0.005 for half a cent.
+// - Relative: The largest difference allowed, as a fraction of the larger of the two numbers' magnitudes.
+// - ULPs: The largest number of representable floating point values allowed
+// between the two numbers, in the precision of the field, float32 or float64.
+type Tolerance struct {
+	Value    any     `json:"value"`
+	Absolute float64 `json:"abs,omitempty"`
+	Relative float64 `json:"rel,omitempty"`
+	ULPs     uint64  `json:"ulps,omitempty"`
+}
+
+// String returns the tolerance, e.g. "0.3 (abs 1e-09, ulps 4)".
+func (t Tolerance) String() string {
+	var tolerances []string
+
+	if t.Absolute != 0 {
+		tolerances = append(tolerances, fmt.Sprintf("abs %v", t.Absolute))
+	}
+
+	if t.Relative != 0 {
+		tolerances = append(tolerances, fmt.Sprintf("rel %v", t.Relative))
+	}
+
+	if t.ULPs != 0 {
+		tolerances = append(tolerances, fmt.Sprintf("ulps %d", t.ULPs))
+	}
+
+	if len(tolerances) == 0 {
+		return fmt.Sprint(t.Value)
+	}
+
+	return fmt.Sprintf("%v (%s)", t.Value, strings.Join(tolerances, ", "))
+}
+
+// contains reports whether v is within the tolerance of t.Value. Numbers that are
+// equal always are, including infinities of the same sign, and NaN never is.
+func (t Tolerance) contains(v any) bool {
+	left, ok := toFloat(v)
+	if !ok {
+		return false
+	}
+
+	right, _ := toFloat(t.Value)
+
+	switch {
+	case math.IsNaN(left) || math.IsNaN(right):
+		return false
+	case left == right:
+		return true
+	case math.IsInf(left, 0) || math.IsInf(right, 0):
+		return false
+	}
+
+	diff := math.Abs(left - right)
+
+	if diff <= t.Absolute || diff <= t.Relative*math.Max(math.Abs(left), math.Abs(right)) {
+		return true
+	}
+
+	if t.ULPs == 0 {
+		return false
+	}
+
+	// float32 fields are compared in their own precision, where a ULP is larger
+	if reflect.ValueOf(utils.DereferencePointer(v)).Kind() == reflect.Float32 {
+		return ulpDistance32(float32(left), float32(right)) <= t.ULPs
+	}
+
+	return ulpDistance(left, right) <= t.ULPs
+}
+
+// toFloat returns v, a number as accepted by toNumber, as the nearest float64.
+func toFloat(v any) (float64, bool) {
+	n, ok := toNumber(utils.DereferencePointer(v))
+	if !ok {
+		return 0, false
+	}
+
+	switch n := n.(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case *big.Rat:
+		f, _ := n.Float64()
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+// ulpDistance returns the number of float64 values from a to b, counting 0 and
+// -0 as one value.
+func ulpDistance(a, b float64) uint64 {
+	ia, ib := orderedBits(int64(math.Float64bits(a))), orderedBits(int64(math.Float64bits(b)))
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+
+	// The difference may not fit an int64, but wraps to the right uint64
+	return uint64(ia) - uint64(ib)
+}
+
+// ulpDistance32 returns the number of float32 values from a to b.
+func ulpDistance32(a, b float32) uint64 {
+	ia, ib := orderedBits(int64(int32(math.Float32bits(a)))), orderedBits(int64(int32(math.Float32bits(b))))
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+
+	return uint64(ia - ib)
+}
+
+// orderedBits maps the bits of a float, as a signed integer, to an integer that
+// is ordered as the floats are, with 0 and -0 both mapped to 0.
+func orderedBits(bits int64) int64 {
+	if bits >= 0 {
+		return bits
+	}
+
+	return math.MinInt64 - bits
+}
+
+// asTolerance converts the value of an "approx_eq" rule to a Tolerance. The
+// value can be a Tolerance, a *Tolerance or the decoded JSON object form.
+func asTolerance(value any) (Tolerance, error) {
+	switch v := value.(type) {
+	case Tolerance:
+		return v, nil
+	case *Tolerance:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]any:
+		return toleranceFromMap(v)
+	}
+
+	return Tolerance{}, newEvaluationError(ErrInvalidRule, "the value of an approx_eq rule must be a tolerance, got %T", value)
+}
+
+// toleranceFromMap converts the JSON object form of a Tolerance.
+func toleranceFromMap(m map[string]any) (Tolerance, error) {
+	var t Tolerance
+
+	for key, value := range m {
+		if key == "value" {
+			t.Value = value
+			continue
+		}
+
+		f, ok := toFloat(value)
+		if !ok {
+			return Tolerance{}, newEvaluationError(ErrInvalidRule, "tolerance key %q must be a number, got %T", key, value)
+		}
+
+		switch key {
+		case "abs":
+			t.Absolute = f
+		case "rel":
+			t.Relative = f
+		case "ulps":
+			if f < 0 || f != math.Trunc(f) || f >= math.MaxUint64 {
+				return Tolerance{}, newEvaluationError(ErrInvalidRule, "tolerance key %q must be a whole number, got %v", key, value)
+			}
+			t.ULPs = uint64(f)
+		default:
+			return Tolerance{}, newEvaluationError(ErrInvalidRule, "unknown tolerance key %q", key)
+		}
+	}
+
+	return t, nil
+}
+
+// prepareTolerance validates the value of an "approx_eq" rule: the value must be
+// a number other than NaN, and at least one tolerance must be set, none of them
+// negative or NaN.
+func prepareTolerance(value any) (any, error) {
+	t, err := asTolerance(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := toFloat(t.Value); !ok || math.IsNaN(f) {
+		return nil, newEvaluationError(ErrInvalidRule, "tolerance %v must have a number to compare with, got %T", t, t.Value)
+	}
+
+	if !(t.Absolute >= 0) || !(t.Relative >= 0) {
+		return nil, newEvaluationError(ErrInvalidRule, "tolerance %v must not be negative", t)
+	}
+
+	if t.Absolute == 0 && t.Relative == 0 && t.ULPs == 0 {
+		return nil, newEvaluationError(ErrInvalidRule, "tolerance %v must set abs, rel or ulps", t)
+	}
+
+	return t, nil
+}
+
+// approxEqualsPolicyCheckOperator checks if the left value is within the
+// Tolerance in the right value. Numbers of any type, and numeric strings, are
+// compared as float64 values, and the operator returns false for NaN and for
+// values that are not numbers.
+var approxEqualsPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	t, ok := rightVal.(Tolerance)
+	if !ok {
+		prepared, err := prepareTolerance(rightVal)
+		if err != nil {
+			return false
+		}
+
+		t = prepared.(Tolerance)
+	}
+
+	return t.contains(leftVal)
+}
@@ -0,0 +1,189 @@
+package go_policy_enforcer
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApproxEqualsPolicyCheckOperator(t *testing.T) {
+	sum := 0.1 + 0.2
+	next := math.Nextafter(1, 2)
+
+	tests := []struct {
+		name     string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"rounding noise", sum, Tolerance{Value: 0.3, Absolute: 1e-9}, true},
+		{"outside absolute", 0.31, Tolerance{Value: 0.3, Absolute: 1e-9}, false},
+		{"on absolute bound", 10.5, Tolerance{Value: 10, Absolute: 0.5}, true},
+		{"relative", 1000001.0, Tolerance{Value: 1e6, Relative: 1e-6}, true},
+		{"outside relative", 1000002.0, Tolerance{Value: 1e6, Relative: 1e-6}, false},
+		{"relative to the larger value", -99.0, Tolerance{Value: -100, Relative: 0.01}, true},
+		{"ulps", sum, Tolerance{Value: 0.3, ULPs: 1}, true},
+		{"outside ulps", next + math.Nextafter(next, 2) - 1, Tolerance{Value: 1, ULPs: 1}, false},
+		{"ulps across zero", math.SmallestNonzeroFloat64, Tolerance{Value: -math.SmallestNonzeroFloat64, ULPs: 2}, true},
+		{"float32 ulps", float32(0.1) + float32(0.2), Tolerance{Value: 0.3, ULPs: 1}, true},
+		{"any tolerance", 0.3 + 1e-6, Tolerance{Value: 0.3, Absolute: 1e-9, Relative: 1e-3}, true},
+		{"integers", 99, Tolerance{Value: 100, Absolute: 1}, true},
+		{"decimal", big.NewRat(1999, 100), Tolerance{Value: 20, Absolute: 0.02}, true},
+		{"pointer", &sum, Tolerance{Value: 0.3, ULPs: 1}, true},
+		{"numeric string", "0.30000000000000004", Tolerance{Value: 0.3, ULPs: 1}, true},
+		{"json object", 19.991, map[string]any{"value": json.Number("19.99"), "abs": json.Number("0.005")}, true},
+		{"equal infinities", math.Inf(1), Tolerance{Value: math.Inf(1), Absolute: 1}, true},
+		{"infinity and a number", math.Inf(1), Tolerance{Value: math.MaxFloat64, Relative: 1}, false},
+		{"opposite infinities", math.Inf(-1), Tolerance{Value: math.Inf(1), ULPs: math.MaxUint64}, false},
+		{"NaN", math.NaN(), Tolerance{Value: 0, Absolute: math.Inf(1)}, false},
+		{"not a number", "abc", Tolerance{Value: 0, Absolute: 1}, false},
+		{"list", []float64{0.3}, Tolerance{Value: 0.3, Absolute: 1}, false},
+		{"invalid tolerance", 0.3, 0.3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := evaluatePolicyCheckOperator("approx_eq", tt.leftVal, tt.rightVal)
+			if err != nil {
+				t.Fatalf("unexpected error for %v approx_eq %v: %v", tt.leftVal, tt.rightVal, err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("%v approx_eq %v = %v; want %v", tt.leftVal, tt.rightVal, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUlpDistance(t *testing.T) {
+	tests := []struct {
+		a, b     float64
+		expected uint64
+	}{
+		{1, 1, 0},
+		{0, math.Copysign(0, -1), 0},
+		{1, math.Nextafter(1, 2), 1},
+		{math.Nextafter(1, 0), math.Nextafter(1, 2), 2},
+		{-math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64, 2},
+		{-math.MaxFloat64, math.MaxFloat64, 0xffdffffffffffffe},
+	}
+
+	for _, tt := range tests {
+		if result := ulpDistance(tt.a, tt.b); result != tt.expected {
+			t.Errorf("ulpDistance(%v, %v) = %d; want %d", tt.a, tt.b, result, tt.expected)
+		}
+
+		if result := ulpDistance(tt.b, tt.a); result != tt.expected {
+			t.Errorf("ulpDistance(%v, %v) = %d; want %d", tt.b, tt.a, result, tt.expected)
+		}
+	}
+
+	if result := ulpDistance32(1, math.Nextafter32(1, 2)); result != 1 {
+		t.Errorf("ulpDistance32() = %d; want 1", result)
+	}
+}
+
+func TestTolerance_String(t *testing.T) {
+	tests := []struct {
+		tolerance Tolerance
+		expected  string
+	}{
+		{Tolerance{Value: 0.3, Absolute: 1e-9}, "0.3 (abs 1e-09)"},
+		{Tolerance{Value: 100, Relative: 0.01, ULPs: 4}, "100 (rel 0.01, ulps 4)"},
+		{Tolerance{Value: 1}, "1"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.tolerance.String(); result != tt.expected {
+			t.Errorf("expected %q, but got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_ApproxEquals(t *testing.T) {
+	type Reading struct {
+		Temperature float64
+		Humidity    float32
+	}
+
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{"Name": "Sensor", "Rules": [
+		{"field": "Temperature", "operator": "approx_eq", "value": {"value": 21.3, "abs": 0.05}},
+		{"field": "Humidity", "operator": "approx_eq", "value": {"value": 0.45, "rel": 1e-6, "ulps": 2}}
+	]}`
+
+	if err := os.WriteFile(policyFile, []byte(policy), 0o644); err != nil {
+		t.Fatalf("failed to create policy file: %v", err)
+	}
+
+	loaded, err := LoadPolicy(policyFile)
+	if err != nil {
+		t.Fatalf("unexpected error loading policy: %v", err)
+	}
+
+	program, err := Compile([]Policy{*loaded}, Reading{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		reading  Reading
+		expected bool
+	}{
+		{"computed values", Reading{Temperature: 20.1 + 1.2, Humidity: 0.15 * 3}, true},
+		{"within the absolute tolerance", Reading{Temperature: 21.34, Humidity: 0.45}, true},
+		{"too warm", Reading{Temperature: 21.36, Humidity: 0.45}, false},
+		{"too humid", Reading{Temperature: 21.3, Humidity: 0.4501}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := loaded.Evaluate(tt.reading); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(tt.reading); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidTolerance(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"not a tolerance", 0.3},
+		{"no tolerance", map[string]any{"value": 0.3}},
+		{"missing value", map[string]any{"abs": 0.1}},
+		{"value not a number", map[string]any{"value": "warm", "abs": 0.1}},
+		{"NaN value", Tolerance{Value: math.NaN(), Absolute: 0.1}},
+		{"negative tolerance", map[string]any{"value": 0.3, "abs": -0.1}},
+		{"NaN tolerance", Tolerance{Value: 0.3, Relative: math.NaN()}},
+		{"fractional ulps", map[string]any{"value": 0.3, "ulps": 1.5}},
+		{"unknown key", map[string]any{"value": 0.3, "epsilon": 0.1}},
+		{"non numeric key", map[string]any{"value": 0.3, "abs": "small"}},
+		{"nil tolerance", (*Tolerance)(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Tolerance", Rules: []Rule{{Field: "Value", Operator: "approx_eq", Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ Value float64 }{Value: 0.3})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ Value float64 }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
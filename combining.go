@@ -0,0 +1,250 @@
+package go_policy_enforcer
+
+import "context"
+
+// Outcome is the result of evaluating a policy, or of combining the results of
+// several policies with a CombiningAlgorithm.
+type Outcome string
+
+const (
+	// OutcomePermit means the resource is allowed.
+	OutcomePermit Outcome = "permit"
+
+	// OutcomeDeny means the resource is denied.
+	OutcomeDeny Outcome = "deny"
+
+	// OutcomeNotApplicable means the policy does not apply to the resource,
+	// either because its Target did not match or because the rules of a policy
+	// with an Effect did not match. When combining, it means no policy applied.
+	OutcomeNotApplicable Outcome = "not-applicable"
+
+	// OutcomeIndeterminate means a decision could not be reached because a
+	// policy failed to evaluate, or because the combining algorithm could not
+	// choose between the policy results.
+	OutcomeIndeterminate Outcome = "indeterminate"
+)
+
+// CombiningAlgorithm selects how a PolicyEnforcer combines the outcomes of its
+// policies into a single decision. A resource is only allowed when the combined
+// outcome is OutcomePermit.
+//
+// Each policy produces OutcomePermit, OutcomeDeny or OutcomeNotApplicable
+// according to its Target and Effect, or OutcomeIndeterminate when it cannot be
+// evaluated. Policies that are not applicable are ignored by every algorithm.
+type CombiningAlgorithm string
+
+const (
+	// DenyOverrides denies if any policy denies. Otherwise, the outcome is
+	// indeterminate if any policy failed to evaluate, and permit if any policy
+	// permits. This is the default, and matches requiring every policy to pass.
+	DenyOverrides CombiningAlgorithm = "deny-overrides"
+
+	// PermitOverrides permits if any policy permits. Otherwise, the outcome is
+	// indeterminate if any policy failed to evaluate, and deny if any policy denies.
+	PermitOverrides CombiningAlgorithm = "permit-overrides"
+
+	// FirstApplicable uses the outcome of the first applicable policy, in the
+	// order the policies were configured.
+	FirstApplicable CombiningAlgorithm = "first-applicable"
+
+	// OnlyOneApplicable uses the outcome of the single applicable policy. The
+	// outcome is indeterminate if more than one policy is applicable.
+	OnlyOneApplicable CombiningAlgorithm = "only-one-applicable"
+
+	// Majority permits when more applicable policies permit than deny. A tie
+	// is a deny.
+	Majority CombiningAlgorithm = "majority"
+)
+
+// valid reports whether the algorithm is known. The zero value is valid and
+// selects DenyOverrides.
+func (a CombiningAlgorithm) valid() bool {
+	switch a {
+	case "", DenyOverrides, PermitOverrides, FirstApplicable, OnlyOneApplicable, Majority:
+		return true
+	default:
+		return false
+	}
+}
+
+// combinePolicies evaluates count policies in order, using evaluate, and combines
+// their outcomes with algorithm. When explain is false, evaluation stops as soon
+// as the combined outcome is known and no per-policy results are recorded.
+//
+// If ctx is done before every policy needed has been evaluated, the decision is
+// OutcomeIndeterminate and its error is ctx.Err().
+func combinePolicies(ctx context.Context, algorithm CombiningAlgorithm, count int, explain bool, evaluate func(i int) PolicyResult) Decision {
+	decision := Decision{}
+
+	c := newCombiner(algorithm)
+	done := false
+
+	for i := 0; i < count; i++ {
+		if err := contextError(ctx); err != nil {
+			return interruptedDecision(decision, err)
+		}
+
+		result := evaluate(i)
+
+		// The policy was interrupted rather than failing on its own
+		if result.Err != nil {
+			if err := contextError(ctx); err != nil {
+				return interruptedDecision(decision, err)
+			}
+		}
+
+		if explain {
+			decision.Policies = append(decision.Policies, result)
+		}
+
+		if !done {
+			done = c.add(result)
+		}
+
+		if done && !explain {
+			break
+		}
+	}
+
+	decision.Outcome, decision.Err = c.result()
+	decision.Allowed = decision.Outcome == OutcomePermit
+
+	if explain {
+		decision.setErrors()
+	}
+
+	return decision
+}
+
+// interruptedDecision returns decision, with the policy results gathered so far,
+// as an indeterminate decision caused by err.
+func interruptedDecision(decision Decision, err error) Decision {
+	decision.Allowed = false
+	decision.Outcome = OutcomeIndeterminate
+	decision.Err = err
+	decision.setErrors()
+
+	return decision
+}
+
+// combiner accumulates policy results for a CombiningAlgorithm. Results are
+// added one at a time so callers can stop evaluating policies as soon as the
+// outcome can no longer change.
+type combiner struct {
+	algorithm CombiningAlgorithm
+
+	applicable     int
+	permits        int
+	denies         int
+	indeterminates int
+
+	first    Outcome
+	firstErr error
+	err      error
+}
+
+// newCombiner returns a combiner for the given algorithm. The zero value
+// algorithm selects DenyOverrides.
+func newCombiner(algorithm CombiningAlgorithm) *combiner {
+	if algorithm == "" {
+		algorithm = DenyOverrides
+	}
+
+	return &combiner{algorithm: algorithm}
+}
+
+// add records the outcome of a single policy. It returns true once the combined
+// outcome is known, after which further results must not be added.
+func (c *combiner) add(result PolicyResult) bool {
+	if result.Outcome == OutcomeNotApplicable {
+		return false
+	}
+
+	c.applicable++
+	if c.applicable == 1 {
+		c.first, c.firstErr = result.Outcome, result.Err
+	}
+
+	switch result.Outcome {
+	case OutcomePermit:
+		c.permits++
+	case OutcomeDeny:
+		c.denies++
+	case OutcomeIndeterminate:
+		c.indeterminates++
+		if c.err == nil {
+			c.err = result.Err
+		}
+	}
+
+	switch c.algorithm {
+	case DenyOverrides:
+		return result.Outcome == OutcomeDeny
+	case PermitOverrides:
+		return result.Outcome == OutcomePermit
+	case FirstApplicable:
+		return true
+	case OnlyOneApplicable:
+		return c.applicable > 1
+	default:
+		return false
+	}
+}
+
+// result returns the combined outcome. The error is set when the outcome is
+// OutcomeIndeterminate and explains why.
+func (c *combiner) result() (Outcome, error) {
+	switch c.algorithm {
+	case DenyOverrides:
+		return c.overrides(OutcomeDeny, c.denies, OutcomePermit, c.permits)
+
+	case PermitOverrides:
+		return c.overrides(OutcomePermit, c.permits, OutcomeDeny, c.denies)
+
+	case FirstApplicable:
+		if c.applicable == 0 {
+			return OutcomeNotApplicable, nil
+		}
+		return c.first, c.firstErr
+
+	case OnlyOneApplicable:
+		switch {
+		case c.applicable == 0:
+			return OutcomeNotApplicable, nil
+		case c.applicable > 1:
+			return OutcomeIndeterminate, newEvaluationError(ErrPolicyConflict, "%d policies are applicable, expected only one", c.applicable)
+		default:
+			return c.first, c.firstErr
+		}
+
+	case Majority:
+		switch {
+		case c.permits > c.denies:
+			return OutcomePermit, nil
+		case c.denies > 0:
+			return OutcomeDeny, nil
+		case c.indeterminates > 0:
+			return OutcomeIndeterminate, c.err
+		default:
+			return OutcomeNotApplicable, nil
+		}
+
+	default:
+		return OutcomeIndeterminate, newEvaluationError(ErrInvalidCombiningAlgorithm, "unknown combining algorithm %q", c.algorithm)
+	}
+}
+
+// overrides implements DenyOverrides and PermitOverrides: the overriding outcome
+// wins if present, then indeterminate, then the other outcome.
+func (c *combiner) overrides(winner Outcome, winners int, other Outcome, others int) (Outcome, error) {
+	switch {
+	case winners > 0:
+		return winner, nil
+	case c.indeterminates > 0:
+		return OutcomeIndeterminate, c.err
+	case others > 0:
+		return other, nil
+	default:
+		return OutcomeNotApplicable, nil
+	}
+}
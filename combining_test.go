@@ -0,0 +1,74 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCombiner_Algorithms(t *testing.T) {
+	permit := PolicyResult{Outcome: OutcomePermit}
+	deny := PolicyResult{Outcome: OutcomeDeny}
+	broken := PolicyResult{Outcome: OutcomeIndeterminate, Err: ErrFieldNotFound}
+	notApplicable := PolicyResult{Outcome: OutcomeNotApplicable}
+
+	tests := []struct {
+		name      string
+		algorithm CombiningAlgorithm
+		results   []PolicyResult
+		expected  Outcome
+	}{
+		{"deny overrides with a deny", DenyOverrides, []PolicyResult{permit, deny, permit}, OutcomeDeny},
+		{"deny overrides all permit", DenyOverrides, []PolicyResult{permit, permit}, OutcomePermit},
+		{"deny overrides with an error", DenyOverrides, []PolicyResult{permit, broken}, OutcomeIndeterminate},
+		{"deny overrides deny beats error", DenyOverrides, []PolicyResult{broken, deny}, OutcomeDeny},
+		{"deny overrides nothing applicable", DenyOverrides, nil, OutcomeNotApplicable},
+		{"zero value is deny overrides", "", []PolicyResult{permit, deny}, OutcomeDeny},
+		{"permit overrides with a permit", PermitOverrides, []PolicyResult{deny, permit, deny}, OutcomePermit},
+		{"permit overrides all deny", PermitOverrides, []PolicyResult{deny, deny}, OutcomeDeny},
+		{"permit overrides with an error", PermitOverrides, []PolicyResult{deny, broken}, OutcomeIndeterminate},
+		{"first applicable", FirstApplicable, []PolicyResult{notApplicable, deny, permit}, OutcomeDeny},
+		{"first applicable nothing applicable", FirstApplicable, []PolicyResult{notApplicable}, OutcomeNotApplicable},
+		{"only one applicable", OnlyOneApplicable, []PolicyResult{notApplicable, permit}, OutcomePermit},
+		{"only one applicable conflict", OnlyOneApplicable, []PolicyResult{permit, deny}, OutcomeIndeterminate},
+		{"majority permits", Majority, []PolicyResult{permit, deny, permit}, OutcomePermit},
+		{"majority denies", Majority, []PolicyResult{permit, deny, deny}, OutcomeDeny},
+		{"majority tie denies", Majority, []PolicyResult{permit, deny}, OutcomeDeny},
+		{"majority only errors", Majority, []PolicyResult{broken}, OutcomeIndeterminate},
+		{"unknown algorithm", "most-recent", []PolicyResult{permit}, OutcomeIndeterminate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCombiner(tt.algorithm)
+			for _, result := range tt.results {
+				if c.add(result) {
+					break
+				}
+			}
+
+			outcome, err := c.result()
+			if outcome != tt.expected {
+				t.Errorf("expected %s, but got %s", tt.expected, outcome)
+			}
+
+			if (outcome == OutcomeIndeterminate) != (err != nil) {
+				t.Errorf("expected an error only for indeterminate outcomes, got %s with error %v", outcome, err)
+			}
+		})
+	}
+}
+
+func TestCombiner_ErrorsForIndeterminateOutcomes(t *testing.T) {
+	c := newCombiner(OnlyOneApplicable)
+	c.add(PolicyResult{Outcome: OutcomePermit})
+	c.add(PolicyResult{Outcome: OutcomePermit})
+
+	if _, err := c.result(); !errors.Is(err, ErrPolicyConflict) {
+		t.Errorf("expected ErrPolicyConflict, but got %v", err)
+	}
+
+	c = newCombiner("most-recent")
+	if _, err := c.result(); !errors.Is(err, ErrInvalidCombiningAlgorithm) {
+		t.Errorf("expected ErrInvalidCombiningAlgorithm, but got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package go_policy_enforcer
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// Comparable is implemented by value types, such as IDs, enums or wrapped
+// UUIDs, that know how to compare themselves with the values of rules. The
+// equality and comparison operators, "between", "in", "not in" and the set
+// operators consult it before any other way of comparing values, whichever side
+// of the rule the Comparable value is on.
+//
+// Values that are not Comparable but implement encoding.TextMarshaler or
+// fmt.Stringer are compared as their text, as strings are.
+type Comparable interface {
+	// ComparePolicyValue compares the receiver with other, a value from a rule or
+	// another field, and returns -1, 0 or +1 as the receiver is less than, equal
+	// to or greater than other. It returns an error when other cannot be
+	// compared with the receiver, in which case the values are neither equal nor
+	// ordered, as with NaN.
+	ComparePolicyValue(other any) (int, error)
+}
+
+var (
+	comparableType    = reflect.TypeOf((*Comparable)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// compareCustom compares two values with ComparePolicyValue when either of them
+// is Comparable, the left value first. handled is false for other values.
+func compareCustom(leftVal, rightVal any) (c int, ok, handled bool) {
+	if left, isComparable := utils.ValueAs[Comparable](leftVal); isComparable {
+		c, err := left.ComparePolicyValue(rightVal)
+		return c, err == nil, true
+	}
+
+	if right, isComparable := utils.ValueAs[Comparable](rightVal); isComparable {
+		c, err := right.ComparePolicyValue(leftVal)
+		return -c, err == nil, true
+	}
+
+	return 0, false, false
+}
+
+// textOf returns the text of v when it implements encoding.TextMarshaler or,
+// failing that, fmt.Stringer.
+func textOf(v any) (string, bool) {
+	if m, ok := utils.ValueAs[encoding.TextMarshaler](v); ok {
+		text, err := m.MarshalText()
+		return string(text), err == nil
+	}
+
+	if s, ok := utils.ValueAs[fmt.Stringer](v); ok {
+		return s.String(), true
+	}
+
+	return "", false
+}
+
+// isCustomValue reports whether v is compared through Comparable, or as its
+// text, rather than only as a value of a built-in kind. Such values, which
+// include times, durations and the math/big numbers, can be equal to values of
+// other types, so they cannot be looked up by key.
+func isCustomValue(v any) bool {
+	return isCustomType(reflect.TypeOf(v))
+}
+
+// isCustomType reports whether the values of type t are custom values, as
+// described by isCustomValue.
+func isCustomType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	if implements(t, comparableType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool:
+		return false
+	}
+
+	return implements(t, textMarshalerType) || implements(t, stringerType)
+}
+
+// implements reports whether values of type t, or pointers to them, implement
+// the interface iface.
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || (t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(iface))
+}
@@ -0,0 +1,236 @@
+package go_policy_enforcer
+
+import (
+	"cmp"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// accountID is compared through Comparable, with other account IDs and with
+// strings such as "ACC-42".
+type accountID struct {
+	number int
+}
+
+func (id accountID) ComparePolicyValue(other any) (int, error) {
+	switch o := other.(type) {
+	case accountID:
+		return cmp.Compare(id.number, o.number), nil
+	case string:
+		number, err := strconv.Atoi(strings.TrimPrefix(o, "ACC-"))
+		if err != nil || !strings.HasPrefix(o, "ACC-") {
+			return 0, fmt.Errorf("%q is not an account ID", o)
+		}
+
+		return cmp.Compare(id.number, number), nil
+	default:
+		return 0, fmt.Errorf("cannot compare an account ID with %T", other)
+	}
+}
+
+// ticketID implements encoding.TextMarshaler on its pointer, as hex.
+type ticketID [4]byte
+
+func (t *ticketID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(t[:])), nil
+}
+
+// logLevel is an enum with names.
+type logLevel int
+
+func (l logLevel) String() string {
+	return [...]string{"debug", "info", "warn", "error"}[l]
+}
+
+// release implements fmt.Stringer only.
+type release struct {
+	major, minor int
+}
+
+func (r release) String() string {
+	return fmt.Sprintf("v%d.%d", r.major, r.minor)
+}
+
+func TestComparableValues(t *testing.T) {
+	ticket := ticketID{0x0a, 0x0b, 0x0c, 0x0d}
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"==", accountID{42}, "ACC-42", true},
+		{"==", "ACC-42", accountID{42}, true}, // Either side can be Comparable
+		{"==", accountID{42}, accountID{42}, true},
+		{"==", &accountID{42}, "ACC-42", true},
+		{"==", accountID{42}, "42", false}, // ComparePolicyValue fails
+		{"!=", accountID{42}, "42", true},
+		{"!=", accountID{42}, "ACC-7", true},
+		{">", accountID{42}, "ACC-7", true}, // By number, not lexically
+		{"<", "ACC-7", accountID{42}, true},
+		{"<=", accountID{42}, 42, false},
+		{"between", accountID{42}, []any{"ACC-10", "ACC-50"}, true},
+		{"between", accountID{42}, []any{"ACC-1", "ACC-9"}, false},
+		{"in", accountID{42}, []any{"ACC-1", "ACC-42"}, true},
+		{"in", accountID{42}, []string{"ACC-1", "ACC-2"}, false},
+		{"not in", accountID{42}, []any{"ACC-1", "ACC-2"}, true},
+		{"intersects", []accountID{{1}, {42}}, []any{"ACC-42"}, true},
+		{"subset_of", []any{"ACC-1", "ACC-42"}, []accountID{{1}, {42}, {7}}, true},
+		{"subset_of", []any{"ACC-1", "ACC-43"}, []accountID{{1}, {42}}, false},
+		{"==", ticket, "0a0b0c0d", true}, // TextMarshaler on the pointer
+		{"in", ticket, []any{"ffffffff", "0a0b0c0d"}, true},
+		{"contains_any", []ticketID{ticket}, []string{"0a0b0c0d"}, true},
+		{"==", logLevel(2), "warn", true}, // Stringer
+		{"==", logLevel(2), 2, true},      // Still a number
+		{"in", logLevel(3), []any{"warn", "error"}, true},
+		{">", release{1, 10}, "v1.1", true},
+		{"==", release{1, 10}, "v1.10", true},
+		{"==", net.ParseIP("10.0.0.1").To4(), "10.0.0.1", true},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_ComparableFields(t *testing.T) {
+	type account struct {
+		ID       accountID
+		Owner    *accountID
+		Ticket   ticketID
+		Level    logLevel
+		Release  release
+		Accounts []accountID
+	}
+
+	input := account{
+		ID:       accountID{42},
+		Owner:    &accountID{7},
+		Ticket:   ticketID{0x0a, 0x0b, 0x0c, 0x0d},
+		Level:    1,
+		Release:  release{2, 0},
+		Accounts: []accountID{{1}, {2}},
+	}
+
+	tests := []struct {
+		name      string
+		rule      Rule
+		expected  bool
+		strictErr error
+	}{
+		{"comparable", Rule{Field: "ID", Operator: "==", Value: "ACC-42"}, true, nil},
+		{"comparable pointer", Rule{Field: "Owner", Operator: "<", Value: "ACC-10"}, true, nil},
+		{"comparable in", Rule{Field: "ID", Operator: "in", Value: []any{"ACC-41", "ACC-42"}}, true, nil},
+		{"comparable not in", Rule{Field: "ID", Operator: "not in", Value: []any{"ACC-41", "ACC-43"}}, true, nil},
+		{"comparable and other kinds", Rule{Field: "ID", Operator: "==", Value: 42}, false, nil},
+		{"comparable list", Rule{Field: "Accounts", Operator: "contains_all", Value: []any{"ACC-2"}}, true, nil},
+		{"text marshaler", Rule{Field: "Ticket", Operator: "==", Value: "0a0b0c0d"}, true, nil},
+		{"text marshaler in", Rule{Field: "Ticket", Operator: "in", Value: []any{"0a0b0c0d"}}, true, nil},
+		{"text marshaler and number", Rule{Field: "Ticket", Operator: "==", Value: 5}, false, ErrTypeMismatch},
+		{"enum", Rule{Field: "Level", Operator: "==", Value: 1}, true, nil},
+		{"stringer", Rule{Field: "Release", Operator: ">=", Value: "v1.9"}, true, nil},
+		{"stringer in", Rule{Field: "Release", Operator: "in", Value: []any{"v1.0", "v2.0"}}, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Account", Rules: []Rule{tt.rule}}
+
+			result, err := policy.EvaluateE(input)
+			if result != tt.expected || err != nil {
+				t.Errorf("EvaluateE() = %v, %v; want %v, nil", result, err, tt.expected)
+			}
+
+			program, err := Compile([]Policy{policy}, account{})
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			if result := program.Enforce(input); result != tt.expected {
+				t.Errorf("compiled Enforce() = %v; want %v", result, tt.expected)
+			}
+
+			policy.StrictTypes = true
+
+			result, err = policy.EvaluateE(input)
+			if !errors.Is(err, tt.strictErr) || (err == nil) != (tt.strictErr == nil) {
+				t.Errorf("EvaluateE() with strict types error = %v; want %v", err, tt.strictErr)
+			}
+
+			if err == nil && result != tt.expected {
+				t.Errorf("EvaluateE() with strict types = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// handle implements fmt.Stringer on its pointer, and dereferences it.
+type handle struct {
+	name string
+}
+
+func (h *handle) String() string {
+	return "@" + h.name
+}
+
+func TestPolicy_Evaluate_NilCustomValues(t *testing.T) {
+	type profile struct {
+		LastLogin *time.Time
+		Handle    *handle
+	}
+
+	tests := []struct {
+		operator string
+		value    any
+		expected bool
+	}{
+		{"==", "@admin", false},
+		{"!=", "@admin", true},
+		{">", "@admin", false},
+		{"in", []any{"@admin", "@root"}, false},
+		{"not in", []any{"@admin", "@root"}, true},
+		{"intersects", []any{"@admin"}, false},
+		{"subset_of", []any{"@admin"}, false},
+	}
+
+	for _, field := range []string{"LastLogin", "Handle"} {
+		for _, tt := range tests {
+			t.Run(field+" "+tt.operator, func(t *testing.T) {
+				policy := Policy{Name: "Profile", Rules: []Rule{{Field: field, Operator: tt.operator, Value: tt.value}}}
+
+				if result, err := policy.EvaluateE(profile{}); result != tt.expected || err != nil {
+					t.Errorf("EvaluateE() = %v, %v; want %v, nil", result, err, tt.expected)
+				}
+
+				program, err := Compile([]Policy{policy}, profile{})
+				if err != nil {
+					t.Fatalf("Compile() error = %v", err)
+				}
+
+				if result := program.Enforce(profile{}); result != tt.expected {
+					t.Errorf("compiled Enforce() = %v; want %v", result, tt.expected)
+				}
+
+				policy.StrictTypes = true
+				if result, err := policy.EvaluateE(profile{}); result != tt.expected || err != nil {
+					t.Errorf("EvaluateE() with strict types = %v, %v; want %v, nil", result, err, tt.expected)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,523 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// Program is an immutable, compiled form of a set of policies. Compiling
+// resolves each rule's field path to struct field indexes, looks up each
+// operator and prepares constant rule values, such as the lookup set for "in",
+// once instead of on every evaluation.
+//
+// A Program implements PolicyEnforcerInterface and is safe for concurrent use.
+type Program struct {
+	policies     []Policy
+	compiled     []*compiledPolicy
+	algorithm    CombiningAlgorithm
+	clock        func() time.Time
+	resourceType reflect.Type
+}
+
+// Compile validates and compiles policies for resources of the same type as
+// exampleType, returning a Program that evaluates them without repeating the
+// per-rule reflection and lookup work that Policy.Evaluate does.
+//
+// When exampleType is an AccessRequest, the types of its subject, resource and
+// environment are only known at evaluation time, so Compile does not check or
+// cache field paths. Operators and rule values are still checked.
+//
+// Compile takes a copy of the policies and looks up their operators once, so
+// later changes to the policies or to the registry are not seen by the Program. Resources of other types can still be evaluated, but do not
+// benefit from the cached field indexes.
+//
+// Unlike evaluation, which reports problems when the affected rule is reached,
+// Compile returns an error for any problem it can detect up front: unknown
+// operators, which are looked up once in the registry given with
+// WithOperatorRegistry or DefaultOperatorRegistry, malformed condition groups, unknown effects, field paths that
+// can never resolve on exampleType and, with strict types, rule values whose type
+// cannot be compared with the field's. Rule errors are returned as a *RuleError.
+//
+// Parameters:
+// - policies: The policies to compile.
+// - exampleType: A value of the resource type, a pointer to one, or its reflect.Type.
+// - options: Optional settings, such as WithCombiningAlgorithm, WithOperatorRegistry, WithClock or WithStrictTypes.
+//
+// Returns:
+// - *Program: The compiled policies.
+// - error: An error if the policies are invalid for exampleType.
+func Compile(policies []Policy, exampleType any, options ...PolicyEnforcerOption) (*Program, error) {
+	t, ok := exampleType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(exampleType)
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, newEvaluationError(ErrTypeMismatch, "example type %v is not a struct", t)
+	}
+
+	settings := PolicyEnforcer{}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	if !settings.Algorithm.valid() {
+		return nil, newEvaluationError(ErrInvalidCombiningAlgorithm, "unknown combining algorithm %q", settings.Algorithm)
+	}
+
+	c := compiler{strict: true, prepare: true, operators: settings.Operators, strictTypes: settings.StrictTypes}
+
+	// Field paths of access requests are rooted in values of any type
+	bindType := t
+	if t == reflect.TypeOf(AccessRequest{}) {
+		bindType = nil
+	}
+
+	program := &Program{
+		policies:     make([]Policy, len(policies)),
+		compiled:     make([]*compiledPolicy, len(policies)),
+		algorithm:    settings.Algorithm,
+		clock:        settings.Clock,
+		resourceType: t,
+	}
+
+	for i, policy := range policies {
+		program.policies[i] = policy
+		program.policies[i].Target = cloneRules(policy.Target)
+		program.policies[i].Rules = cloneRules(policy.Rules)
+
+		compiled, err := c.compilePolicy(&program.policies[i], bindType)
+		if err != nil {
+			return nil, err
+		}
+
+		program.compiled[i] = compiled
+	}
+
+	return program, nil
+}
+
+// Enforce checks if a given resource complies with the compiled policies. It
+// behaves exactly like PolicyEnforcer.Enforce.
+func (p *Program) Enforce(resource any) bool {
+	return p.decide(context.Background(), resource, false).Allowed
+}
+
+// EnforceE checks if a given resource complies with the compiled policies,
+// returning an error when no decision could be reached. It behaves exactly
+// like PolicyEnforcer.EnforceE.
+func (p *Program) EnforceE(resource any) (bool, error) {
+	return p.EnforceContext(context.Background(), resource)
+}
+
+// EnforceContext checks if a given resource complies with the compiled policies,
+// stopping early when ctx is done. It behaves exactly like
+// PolicyEnforcer.EnforceContext.
+func (p *Program) EnforceContext(ctx context.Context, resource any) (bool, error) {
+	decision := p.decide(ctx, resource, false)
+	return decision.Allowed, decision.Err
+}
+
+// Match returns the compiled policies whose target and rules match the
+// resource. It behaves exactly like PolicyEnforcer.Match.
+func (p *Program) Match(resource any) []*Policy {
+	policies, _ := p.MatchContext(context.Background(), resource)
+	return policies
+}
+
+// MatchContext returns the compiled policies whose target and rules match the
+// resource, stopping early when ctx is done. It behaves exactly like
+// PolicyEnforcer.MatchContext.
+func (p *Program) MatchContext(ctx context.Context, resource any) ([]*Policy, error) {
+	ctx = withClock(ctx, p.clock)
+
+	return matchPolicies(ctx, len(p.compiled), func(i int) (*Policy, PolicyResult) {
+		return &p.policies[i], p.compiled[i].evaluate(ctx, resource, false)
+	})
+}
+
+// Decide evaluates a resource against every compiled policy and returns a
+// Decision that explains the outcome. It behaves exactly like
+// PolicyEnforcer.Decide.
+func (p *Program) Decide(resource any) Decision {
+	return p.decide(context.Background(), resource, true)
+}
+
+func (p *Program) decide(ctx context.Context, resource any, explain bool) Decision {
+	ctx = withClock(ctx, p.clock)
+
+	return combinePolicies(ctx, p.algorithm, len(p.compiled), explain, func(i int) PolicyResult {
+		return p.compiled[i].evaluate(ctx, resource, explain)
+	})
+}
+
+// compiler turns policies into compiledPolicy values.
+type compiler struct {
+	// strict makes compilation fail on the first problem found. Otherwise the
+	// problem is stored on the rule and reported when the rule is evaluated.
+	strict bool
+
+	// prepare binds constant rule values into prepared comparisons.
+	prepare bool
+
+	// operators resolves rule operators. Nil means DefaultOperatorRegistry.
+	operators *OperatorRegistry
+
+	// allowUnknownOperators stops strict compilation failing on operators that
+	// are not registered yet.
+	allowUnknownOperators bool
+
+	// strictTypes compares values without coercing them. See WithStrictTypes.
+	strictTypes bool
+
+	// eager compiles every rule up front even when compilation is not strict,
+	// so the compiled policy can be kept and evaluated repeatedly.
+	eager bool
+}
+
+var (
+	// lenientCompiler is used to evaluate policies directly, and reports
+	// problems at the point evaluation reaches them, exactly as if the policy
+	// had not been compiled at all.
+	lenientCompiler = compiler{}
+
+	// loadCompiler is used to check policies as they are loaded. Operators may
+	// be registered after a policy is loaded, so only the operators that are
+	// already known have their values checked.
+	loadCompiler = compiler{strict: true, allowUnknownOperators: true}
+)
+
+// compiledPolicy is a policy whose target and rules have been compiled. The
+// lenient compiler leaves target and rules nil, unless it is eager, and each
+// rule is then compiled just before it is evaluated so rules that are never
+// reached cost nothing.
+type compiledPolicy struct {
+	policy   *Policy
+	compiler compiler
+	target   []*compiledRule
+	rules    []*compiledRule
+
+	// err is set when the policy itself is invalid
+	err error
+}
+
+// compiledRule is a rule with its field path parsed, its operator looked up and,
+// optionally, its value prepared, ready to be evaluated repeatedly.
+type compiledRule struct {
+	rule *Rule
+
+	// group is the combinator of a condition group, whose rules are in children
+	group    string
+	children []*compiledRule
+
+	path   *fieldPath
+	nested []*compiledRule
+
+	// ref is the path of the field compared against, when the rule's value is a FieldRef
+	ref *fieldPath
+
+	operator    registeredOperator
+	operatorErr error
+
+	// strictTypes compares values without coercing them
+	strictTypes bool
+
+	// value is the rule's value as passed to the operator, after preparation
+	value any
+
+	// prepared compares a field value against the rule's value, which has
+	// been bound ahead of time. It is nil when there is nothing to prepare.
+	prepared func(ctx context.Context, leftVal any) (bool, error)
+
+	// err is set when the rule itself is invalid
+	err error
+}
+
+// compilePolicy compiles the policy's target and rules, binding field paths to
+// t when it is not nil.
+func (c compiler) compilePolicy(p *Policy, t reflect.Type) (*compiledPolicy, error) {
+	if p.StrictTypes {
+		c.strictTypes = true
+	}
+
+	cp := &compiledPolicy{policy: p, compiler: c}
+
+	if p.Effect != "" && p.Effect != EffectAllow && p.Effect != EffectDeny {
+		cp.err = newEvaluationError(ErrInvalidRule, "policy %q has unknown effect %q", p.Name, p.Effect)
+		if c.strict {
+			return nil, cp.err
+		}
+	}
+
+	if !c.strict && !c.eager {
+		return cp, nil
+	}
+
+	var err error
+
+	if cp.target, err = c.compileRules(p, p.Target, t); err != nil {
+		return nil, err
+	}
+
+	if cp.rules, err = c.compileRules(p, p.Rules, t); err != nil {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// compileRules compiles each rule, wrapping any error in a *RuleError.
+func (c compiler) compileRules(p *Policy, rules []Rule, t reflect.Type) ([]*compiledRule, error) {
+	compiled := make([]*compiledRule, len(rules))
+
+	for i := range rules {
+		cr, err := c.compileRule(&rules[i], t)
+		if err != nil {
+			return nil, &RuleError{Policy: p.Name, Rule: rules[i], Err: err}
+		}
+
+		compiled[i] = cr
+	}
+
+	return compiled, nil
+}
+
+// compileRule compiles a single rule, and any rules it contains, for resources
+// of type t. A nil t means the resource type is not known ahead of time.
+func (c compiler) compileRule(rule *Rule, t reflect.Type) (*compiledRule, error) {
+	cr := &compiledRule{rule: rule, value: rule.Value, strictTypes: c.strictTypes}
+
+	if rule.isGroup() {
+		return cr, c.compileGroup(cr, t)
+	}
+
+	cr.path = parseFieldPath(rule.Field)
+	cr.operator, cr.operatorErr = c.operators.lookup(rule.Operator)
+
+	// Operators such as "not_exists" expect fields that may not be there
+	fieldType, err := cr.path.bind(t)
+	if err != nil && c.strict && !(cr.operator.missingFields && isMissingFieldError(err)) {
+		return nil, err
+	}
+
+	if nestedRules, ok := rule.Value.([]Rule); ok {
+		elemType := sliceElemType(fieldType)
+
+		cr.nested = make([]*compiledRule, len(nestedRules))
+		for i := range nestedRules {
+			if cr.nested[i], err = c.compileRule(&nestedRules[i], elemType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ref, isRef, err := asFieldRef(rule.Value)
+	switch {
+	case err != nil:
+		cr.err = err
+		if c.strict {
+			return nil, err
+		}
+		return cr, nil
+	case isRef:
+		cr.ref = parseFieldPath(ref.Field)
+		if _, err := cr.ref.bind(t); err != nil && c.strict {
+			return nil, err
+		}
+	}
+
+	if cr.operatorErr != nil {
+
+		// Nested rules only use the operator when the field is not a slice
+		if c.strict && cr.nested == nil && !c.allowUnknownOperators {
+			return nil, cr.operatorErr
+		}
+
+		return cr, nil
+	}
+
+	// Operators such as "matches" validate and convert the rule's value once
+	if cr.operator.prepare != nil && cr.ref == nil {
+		if cr.value, cr.err = cr.operator.prepare(rule.Value); cr.err != nil {
+			if c.strict {
+				return nil, cr.err
+			}
+			return cr, nil
+		}
+	}
+
+	var (
+		kinds       []string
+		strictTypes bool
+	)
+
+	if c.strictTypes && cr.ref == nil {
+		kinds, strictTypes = cr.operator.valueKinds(cr.value)
+	}
+
+	// The type of the field is known, so a rule that can never compare is an error
+	if strictTypes && c.strict && cr.nested == nil {
+		if err := checkKinds(fieldKinds(fieldType), kinds); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.prepare && cr.ref == nil {
+		cr.prepared = prepareComparison(rule.Operator, cr.operator, cr.value)
+
+		if cr.prepared != nil && strictTypes {
+			cr.prepared = strictComparison(cr.prepared, kinds)
+		}
+	}
+
+	return cr, nil
+}
+
+// compileGroup compiles the rules of an all, any or not condition group.
+func (c compiler) compileGroup(cr *compiledRule, t reflect.Type) error {
+	rule := cr.rule
+
+	if cr.err = rule.validateGroup(); cr.err != nil {
+		if c.strict {
+			return cr.err
+		}
+		return nil
+	}
+
+	var children []Rule
+
+	switch {
+	case rule.All != nil:
+		cr.group, children = allGroupOperator, rule.All
+	case rule.Any != nil:
+		cr.group, children = anyGroupOperator, rule.Any
+	default:
+		cr.group, children = notGroupOperator, []Rule{*rule.Not}
+	}
+
+	cr.children = make([]*compiledRule, len(children))
+	for i := range children {
+		var err error
+		if cr.children[i], err = c.compileRule(&children[i], t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prepareComparison returns a comparison with the rule's constant value bound
+// ahead of time, or nil when the operator has nothing worth preparing. The
+// prepared comparison must behave exactly like applyPolicyCheckOperator.
+func prepareComparison(operator string, op registeredOperator, value any) func(ctx context.Context, leftVal any) (bool, error) {
+	switch operator {
+	case "in", "not in":
+		set, ok := newMembershipSet(value)
+		if !ok {
+			return nil
+		}
+
+		negate := operator == "not in"
+
+		return func(ctx context.Context, leftVal any) (bool, error) {
+			leftVal = utils.DereferencePointer(leftVal)
+
+			// Slices, values that cannot be map keys, and values that are not
+			// compared by key, such as times and Comparable values, take the
+			// regular path
+			if leftVal == nil || isSlice(leftVal) || !reflect.TypeOf(leftVal).Comparable() || isCustomValue(leftVal) {
+				return applyPolicyCheckOperator(ctx, operator, op, leftVal, value)
+			}
+
+			_, found := set[membershipKey(leftVal)]
+			return found != negate, nil
+		}
+	}
+
+	return nil
+}
+
+// newMembershipSet builds a lookup set from a slice rule value. It returns false
+// when the value is not a non-nil slice or any element cannot be a map key or
+// is not compared by key, as checked by isCustomValue.
+func newMembershipSet(value any) (map[any]struct{}, bool) {
+	v := reflect.ValueOf(utils.DereferencePointer(value))
+	if v.Kind() != reflect.Slice || v.IsNil() {
+		return nil, false
+	}
+
+	set := make(map[any]struct{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i).Interface()
+		if elem != nil && (!reflect.TypeOf(elem).Comparable() || isCustomValue(elem)) {
+			return nil, false
+		}
+		set[membershipKey(elem)] = struct{}{}
+	}
+
+	return set, true
+}
+
+// membershipKey returns the key of v in a membership set. Numbers, including
+// json.Number values, are keyed by value and strings of any type by content, as
+// "in" compares them, so the string "7" is not the key of a number. Other
+// values are their own keys.
+func membershipKey(v any) any {
+	if n, ok := numberOf(v); ok {
+		return numberKey(n)
+	}
+
+	if s, ok := stringOf(v); ok {
+		return s
+	}
+
+	return v
+}
+
+// sliceElemType returns the element type of a slice or array type, following
+// pointers, or nil when t is not known to be a slice or array.
+func sliceElemType(t reflect.Type) reflect.Type {
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return nil
+	}
+
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return elem
+}
+
+// cloneRules returns a deep copy of rules, so a compiled program is not affected
+// by later changes to the rules it was compiled from. Rule values are not copied.
+func cloneRules(rules []Rule) []Rule {
+	if rules == nil {
+		return nil
+	}
+
+	cloned := make([]Rule, len(rules))
+	for i, rule := range rules {
+		cloned[i] = rule
+		cloned[i].All = cloneRules(rule.All)
+		cloned[i].Any = cloneRules(rule.Any)
+
+		if rule.Not != nil {
+			not := cloneRules([]Rule{*rule.Not})[0]
+			cloned[i].Not = &not
+		}
+
+		if nested, ok := rule.Value.([]Rule); ok {
+			cloned[i].Value = cloneRules(nested)
+		}
+	}
+
+	return cloned
+}
@@ -0,0 +1,361 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type compileTestAddress struct {
+	City string
+}
+
+type compileTestResource struct {
+	Name    string
+	Age     int
+	Country string
+	Tags    []string
+	Labels  map[string]string
+	Address *compileTestAddress
+	Items   []compileTestAddress
+	secret  string
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		expected error
+	}{
+		{"unknown operator", Policy{Name: "p", Rules: []Rule{{Field: "Name", Operator: "~=", Value: "x"}}}, ErrOperatorNotSupported},
+		{"missing field", Policy{Name: "p", Rules: []Rule{{Field: "Missing", Operator: "==", Value: 1}}}, ErrFieldNotFound},
+		{"missing nested field", Policy{Name: "p", Rules: []Rule{{Field: "Address.Street", Operator: "==", Value: "x"}}}, ErrFieldNotFound},
+		{"unexported field", Policy{Name: "p", Rules: []Rule{{Field: "secret", Operator: "==", Value: "x"}}}, ErrUnexportedField},
+		{"index into non slice", Policy{Name: "p", Rules: []Rule{{Field: "Name[0]", Operator: "==", Value: "x"}}}, ErrTypeMismatch},
+		{"missing field in nested rule", Policy{Name: "p", Rules: []Rule{{Field: "Items", Operator: "any", Value: []Rule{{Field: "Town", Operator: "==", Value: "x"}}}}}, ErrFieldNotFound},
+		{"missing field in target", Policy{Name: "p", Target: []Rule{{Field: "Missing", Operator: "==", Value: 1}}}, ErrFieldNotFound},
+		{"invalid group", Policy{Name: "p", Rules: []Rule{{All: []Rule{}, Any: []Rule{}}}}, ErrInvalidRule},
+		{"unknown effect", Policy{Name: "p", Effect: "maybe"}, ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile([]Policy{tt.policy}, compileTestResource{})
+			if program != nil {
+				t.Errorf("expected no program, but got one")
+			}
+
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected error wrapping %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCompile_RuleErrorIdentifiesRule(t *testing.T) {
+	policies := []Policy{
+		{
+			Name: "Adults",
+			Rules: []Rule{
+				{Field: "Age", Operator: ">=", Value: 18},
+				{Field: "Country", Operator: "~=", Value: "US"},
+			},
+		},
+	}
+
+	_, err := Compile(policies, compileTestResource{})
+
+	var ruleErr *RuleError
+	if !errors.As(err, &ruleErr) {
+		t.Fatalf("expected a *RuleError, but got %v", err)
+	}
+
+	if ruleErr.Policy != "Adults" || ruleErr.Rule.Field != "Country" {
+		t.Errorf("unexpected RuleError: %+v", ruleErr)
+	}
+}
+
+func TestCompile_ExampleTypes(t *testing.T) {
+	policies := []Policy{{Name: "p", Rules: []Rule{{Field: "Age", Operator: ">", Value: 1}}}}
+
+	tests := []struct {
+		name        string
+		exampleType any
+		expected    error
+	}{
+		{"value", compileTestResource{}, nil},
+		{"pointer", &compileTestResource{}, nil},
+		{"reflect type", reflect.TypeOf(compileTestResource{}), nil},
+		{"nil", nil, ErrTypeMismatch},
+		{"not a struct", 42, ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(policies, tt.exampleType)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected error %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCompile_InvalidCombiningAlgorithm(t *testing.T) {
+	_, err := Compile(nil, compileTestResource{}, WithCombiningAlgorithm("unanimous"))
+	if !errors.Is(err, ErrInvalidCombiningAlgorithm) {
+		t.Errorf("expected ErrInvalidCombiningAlgorithm, but got %v", err)
+	}
+}
+
+func TestProgram_MatchesPolicyEnforcer(t *testing.T) {
+	policies := []Policy{
+		{
+			Name: "Adults",
+			Rules: []Rule{
+				{Field: "Age", Operator: ">=", Value: 18},
+				{Field: "Country", Operator: "in", Value: []string{"US", "CA", "GB"}},
+			},
+		},
+		{
+			Name: "Labelled",
+			Rules: []Rule{
+				{Field: "Labels.team", Operator: "==", Value: "core"},
+				{Field: "Address.City", Operator: "not in", Value: []string{"Nowhere"}},
+			},
+		},
+		{
+			Name: "Tagged",
+			Rules: []Rule{
+				{Any: []Rule{
+					{Field: "Tags", Operator: "==", Value: []string{"b", "a"}},
+					{Field: "Items[0].City", Operator: "==", Value: "Paris"},
+				}},
+				{Field: "Items", Operator: "any", Value: []Rule{
+					{Field: "City", Operator: "==", Value: "Paris"},
+				}},
+			},
+		},
+	}
+
+	resources := []compileTestResource{
+		{
+			Age: 30, Country: "US", Tags: []string{"a", "b"},
+			Labels:  map[string]string{"team": "core"},
+			Address: &compileTestAddress{City: "Seattle"},
+			Items:   []compileTestAddress{{City: "Paris"}},
+		},
+		{
+			Age: 30, Country: "FR", Tags: []string{"a", "b"},
+			Labels:  map[string]string{"team": "core"},
+			Address: &compileTestAddress{City: "Seattle"},
+			Items:   []compileTestAddress{{City: "Paris"}},
+		},
+		{
+			Age: 30, Country: "CA",
+			Labels:  map[string]string{"team": "core"},
+			Address: &compileTestAddress{City: "Nowhere"},
+		},
+		{
+			Age: 12, Country: "GB",
+			Labels: map[string]string{},
+		},
+	}
+
+	for _, algorithm := range []CombiningAlgorithm{DenyOverrides, PermitOverrides, Majority} {
+		enforcer := NewPolicyEnforcer(&policies, WithCombiningAlgorithm(algorithm))
+
+		program, err := Compile(policies, compileTestResource{}, WithCombiningAlgorithm(algorithm))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for i, resource := range resources {
+			expected, expectedErr := enforcer.EnforceE(resource)
+			result, resultErr := program.EnforceE(resource)
+
+			if result != expected || (resultErr == nil) != (expectedErr == nil) {
+				t.Errorf("%s: resource %d: expected (%v, %v), but got (%v, %v)", algorithm, i, expected, expectedErr, result, resultErr)
+			}
+
+			if d1, d2 := enforcer.Decide(resource).String(), program.Decide(resource).String(); d1 != d2 {
+				t.Errorf("%s: resource %d: expected decision\n%s\nbut got\n%s", algorithm, i, d1, d2)
+			}
+
+			if m1, m2 := len(enforcer.Match(resource)), len(program.Match(resource)); m1 != m2 {
+				t.Errorf("%s: resource %d: expected %d matches, but got %d", algorithm, i, m1, m2)
+			}
+		}
+	}
+}
+
+func TestProgram_EvaluatesOtherResourceTypes(t *testing.T) {
+	program, err := Compile([]Policy{
+		{Name: "p", Rules: []Rule{{Field: "Name", Operator: "==", Value: "alice"}}},
+	}, compileTestResource{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other := struct {
+		ID   int
+		Name string
+	}{ID: 1, Name: "alice"}
+
+	if !program.Enforce(other) {
+		t.Errorf("expected Enforce to return true for a different resource type, but got false")
+	}
+
+	if !program.Enforce(&compileTestResource{Name: "alice"}) {
+		t.Errorf("expected Enforce to return true for a pointer resource, but got false")
+	}
+
+	if _, err := program.EnforceE(42); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch for a non struct resource, but got %v", err)
+	}
+}
+
+func TestProgram_IsNotAffectedByLaterChanges(t *testing.T) {
+	policies := []Policy{
+		{Name: "p", Rules: []Rule{{Field: "Country", Operator: "in", Value: []string{"US"}}}},
+	}
+
+	program, err := Compile(policies, compileTestResource{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policies[0].Rules[0].Value = []string{"FR"}
+	policies[0].Name = "changed"
+
+	if !program.Enforce(compileTestResource{Country: "US"}) {
+		t.Errorf("expected Enforce to return true, but got false")
+	}
+
+	if matches := program.Match(compileTestResource{Country: "US"}); len(matches) != 1 || matches[0].Name != "p" {
+		t.Errorf("expected the original policy to match, but got %v", matches)
+	}
+}
+
+func TestPrepareComparison_MembershipSet(t *testing.T) {
+	in, _ := DefaultOperatorRegistry.lookup("in")
+	notIn, _ := DefaultOperatorRegistry.lookup("not in")
+
+	tests := []struct {
+		name     string
+		operator string
+		op       registeredOperator
+		value    any
+		left     any
+	}{
+		{"string found", "in", in, []string{"a", "b"}, "b"},
+		{"string missing", "in", in, []string{"a", "b"}, "c"},
+		{"not in", "not in", notIn, []string{"a", "b"}, "c"},
+		{"int found", "in", in, []int{1, 2, 3}, 2},
+		{"mixed types", "in", in, []any{1, "2"}, "2"},
+		{"pointer left", "in", in, []string{"a"}, func() *string { s := "a"; return &s }()},
+		{"slice left", "in", in, []string{"a", "b"}, []string{"b"}},
+		{"nil left", "in", in, []string{"a"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepared := prepareComparison(tt.operator, tt.op, tt.value)
+			if prepared == nil {
+				t.Fatalf("expected a prepared comparison")
+			}
+
+			ctx := context.Background()
+
+			expected, expectedErr := applyPolicyCheckOperator(ctx, tt.operator, tt.op, tt.left, tt.value)
+			result, err := prepared(ctx, tt.left)
+
+			if result != expected || (err == nil) != (expectedErr == nil) {
+				t.Errorf("expected (%v, %v), but got (%v, %v)", expected, expectedErr, result, err)
+			}
+		})
+	}
+}
+
+func TestProgram_MatchesPolicyEnforcer_DecodedPolicies(t *testing.T) {
+	type product struct {
+		Code   string
+		Number int
+		Price  float64
+	}
+
+	policies := []string{
+		`{"Name": "p", "Rules": [{"field": "Code", "operator": "in", "value": [7, 19.99]}]}`,
+		`{"Name": "p", "Rules": [{"field": "Code", "operator": "not in", "value": [7, 19.99]}]}`,
+		`{"Name": "p", "Rules": [{"field": "Code", "operator": "in", "value": ["7", "19.99"]}]}`,
+		`{"Name": "p", "Rules": [{"field": "Number", "operator": "in", "value": [7, 19.99]}]}`,
+		`{"Name": "p", "Rules": [{"field": "Number", "operator": "in", "value": ["7"]}]}`,
+		`{"Name": "p", "Rules": [{"field": "Price", "operator": "in", "value": [7, 19.99]}]}`,
+		`{"Name": "p", "Rules": [{"field": "Price", "operator": "not in", "value": [19.99]}]}`,
+	}
+
+	resources := []product{
+		{Code: "7", Number: 7, Price: 7},
+		{Code: "19.99", Number: 19, Price: 19.99},
+		{Code: "x", Number: 8, Price: 8},
+	}
+
+	for _, data := range policies {
+		var policy Policy
+		if err := decodePolicy([]byte(data), &policy); err != nil {
+			t.Fatalf("unexpected error decoding %s: %v", data, err)
+		}
+
+		enforcer := NewPolicyEnforcer(&[]Policy{policy})
+
+		program, err := Compile([]Policy{policy}, product{})
+		if err != nil {
+			t.Fatalf("unexpected error compiling %s: %v", data, err)
+		}
+
+		for _, resource := range resources {
+			expected, expectedErr := enforcer.EnforceE(resource)
+			result, resultErr := program.EnforceE(resource)
+
+			if result != expected || (resultErr == nil) != (expectedErr == nil) {
+				t.Errorf("%s: %+v: expected (%v, %v), but got (%v, %v)", data, resource, expected, expectedErr, result, resultErr)
+			}
+		}
+	}
+
+	// A string field does not match a number in a policy file
+	var policy Policy
+	if err := decodePolicy([]byte(policies[0]), &policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if NewPolicyEnforcer(&[]Policy{policy}).Enforce(product{Code: "7"}) {
+		t.Errorf("expected the string \"7\" not to be in [7, 19.99]")
+	}
+}
+
+func TestProgram_EnforceContext(t *testing.T) {
+	program, err := Compile([]Policy{
+		{Name: "p", Rules: []Rule{{Field: "Country", Operator: "in", Value: []string{"US"}}}},
+	}, compileTestResource{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resource := compileTestResource{Country: "US"}
+
+	if ok, err := program.EnforceContext(context.Background(), resource); !ok || err != nil {
+		t.Errorf("expected (true, nil), but got (%v, %v)", ok, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if ok, err := program.EnforceContext(ctx, resource); ok || err != context.Canceled {
+		t.Errorf("expected (false, context.Canceled), but got (%v, %v)", ok, err)
+	}
+
+	if matches, err := program.MatchContext(ctx, resource); matches != nil || err != context.Canceled {
+		t.Errorf("expected (nil, context.Canceled), but got (%v, %v)", matches, err)
+	}
+}
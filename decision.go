@@ -0,0 +1,223 @@
+package go_policy_enforcer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decision describes the outcome of evaluating a resource against every policy
+// held by a PolicyEnforcer, along with the result of each individual rule.
+//
+// The Decision struct has the following fields:
+// - Allowed: True when the resource complies with the policies, exactly as Enforce would report.
+// - Outcome: The outcome produced by the enforcer's CombiningAlgorithm. Allowed is true only for OutcomePermit.
+// - Policies: The result of each policy, in the order the policies were configured.
+// - Err: Set when Outcome is OutcomeIndeterminate and explains why.
+// - Error: The message of Err, so that it is kept when the decision is encoded as JSON. It is only
+// set by Decide, as formatting it would slow down Enforce.
+type Decision struct {
+	Allowed  bool           `json:"allowed"`
+	Outcome  Outcome        `json:"outcome"`
+	Policies []PolicyResult `json:"policies"`
+	Err      error          `json:"-"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// PolicyResult describes the outcome of evaluating a resource against a single policy.
+//
+// The PolicyResult struct has the following fields:
+// - Policy: The policy that was evaluated.
+// - Passed: True when the policy applied and the resource satisfied every rule in the policy.
+// - Outcome: The policy's decision. OutcomeNotApplicable when the target did not match, or when
+// the rules did not match a policy with an Effect. Otherwise the Effect when the rules matched,
+// or, for a policy without an Effect, OutcomePermit when they matched and OutcomeDeny when not.
+// OutcomeIndeterminate when the policy could not be evaluated.
+// - Target: The result of each target rule.
+// - Rules: The result of each rule, in the order the rules are declared.
+// - Err: Set when the policy could not be evaluated: the resource is not a struct, or a
+// rule failed with an error, in which case Err is a *RuleError for the first such rule.
+// - Error: The message of Err.
+type PolicyResult struct {
+	Policy  *Policy      `json:"policy"`
+	Passed  bool         `json:"passed"`
+	Outcome Outcome      `json:"outcome"`
+	Target  []RuleResult `json:"target,omitempty"`
+	Rules   []RuleResult `json:"rules,omitempty"`
+	Err     error        `json:"-"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// RuleResult describes the outcome of evaluating a single rule.
+//
+// The RuleResult struct has the following fields:
+// - Field: The field path that was read from the resource.
+// - Value: The value resolved from the resource at Field.
+// - Operator: The operator used for the comparison.
+// - Expected: The value from the rule that Value was compared against, or the value of
+// the referenced field when the rule's value is a FieldRef.
+// - ExpectedField: The field path Expected was read from, when the rule's value is a FieldRef.
+// - Passed: True when the comparison succeeded.
+// - Err: Set when the field could not be resolved or the operator failed.
+// - Error: The message of Err.
+// - Rules: The results of any nested rules, with Field set to the indexed element path,
+// or of the rules within a condition group, in which case Operator is "all", "any" or "not".
+type RuleResult struct {
+	Field         string       `json:"field"`
+	Value         any          `json:"value"`
+	Operator      string       `json:"operator"`
+	Expected      any          `json:"expected"`
+	ExpectedField string       `json:"expectedField,omitempty"`
+	Passed        bool         `json:"passed"`
+	Err           error        `json:"-"`
+	Error         string       `json:"error,omitempty"`
+	Rules         []RuleResult `json:"rules,omitempty"`
+}
+
+// MarshalJSON encodes the rule result as JSON. JSON has no NaN or infinities,
+// so a non-finite number read from the resource or expected by the rule is
+// encoded as the string "NaN", "+Inf" or "-Inf", and other values that JSON
+// cannot hold as their text.
+func (r RuleResult) MarshalJSON() ([]byte, error) {
+	// ruleResult has the fields of RuleResult but not this method
+	type ruleResult RuleResult
+
+	encoded := ruleResult(r)
+	encoded.Value = jsonValue(r.Value)
+	encoded.Expected = jsonValue(r.Expected)
+
+	return json.Marshal(encoded)
+}
+
+// jsonValue returns v, or a string standing in for it when v cannot be encoded
+// as JSON, as described by RuleResult.MarshalJSON.
+func jsonValue(v any) any {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64 {
+		if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+
+		return v
+	}
+
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return v
+}
+
+// setErrors sets the Error field of the decision, and of each of its policy
+// and rule results, to the message of its Err.
+func (d *Decision) setErrors() {
+	d.Error = errorMessage(d.Err)
+
+	for i := range d.Policies {
+		p := &d.Policies[i]
+
+		p.Error = errorMessage(p.Err)
+		setRuleErrors(p.Target)
+		setRuleErrors(p.Rules)
+	}
+}
+
+// setRuleErrors sets the Error field of each rule result, and of the results
+// nested within it, to the message of its Err.
+func setRuleErrors(results []RuleResult) {
+	for i := range results {
+		results[i].Error = errorMessage(results[i].Err)
+		setRuleErrors(results[i].Rules)
+	}
+}
+
+// errorMessage returns the message of err, or "" when err is nil.
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// String renders the decision as an indented, human-readable explanation.
+// Each policy is listed with its result, followed by every rule that was
+// evaluated, the value read from the resource and any error encountered.
+func (d Decision) String() string {
+	var sb strings.Builder
+
+	if d.Allowed {
+		sb.WriteString("allowed")
+	} else {
+		sb.WriteString("denied")
+	}
+
+	if d.Outcome != "" {
+		fmt.Fprintf(&sb, " (%s)", d.Outcome)
+	}
+
+	if d.Err != nil {
+		fmt.Fprintf(&sb, " (error: %v)", d.Err)
+	}
+	sb.WriteString("\n")
+
+	for _, p := range d.Policies {
+		name := ""
+		if p.Policy != nil {
+			name = p.Policy.Name
+		}
+
+		fmt.Fprintf(&sb, "  policy %q: %s", name, passFail(p.Passed))
+		if p.Outcome != "" {
+			fmt.Fprintf(&sb, " (%s)", p.Outcome)
+		}
+		if p.Err != nil {
+			fmt.Fprintf(&sb, " (error: %v)", p.Err)
+		}
+		sb.WriteString("\n")
+
+		if len(p.Target) > 0 {
+			sb.WriteString("    target:\n")
+			writeRuleResults(&sb, p.Target, 3)
+		}
+
+		writeRuleResults(&sb, p.Rules, 2)
+	}
+
+	return sb.String()
+}
+
+// writeRuleResults writes one line per rule result, recursing into nested rules
+// with increased indentation.
+func writeRuleResults(sb *strings.Builder, results []RuleResult, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	for _, r := range results {
+		switch {
+		case r.Field == "" && r.Expected == nil:
+			// Condition groups only have an operator
+			fmt.Fprintf(sb, "%s%s: %s", indent, r.Operator, passFail(r.Passed))
+		case r.ExpectedField != "":
+			fmt.Fprintf(sb, "%s%s %s %s: %s (got %v, %s is %v)", indent, r.Field, r.Operator, r.ExpectedField, passFail(r.Passed), r.Value, r.ExpectedField, r.Expected)
+		default:
+			fmt.Fprintf(sb, "%s%s %s %v: %s (got %v)", indent, r.Field, r.Operator, r.Expected, passFail(r.Passed), r.Value)
+		}
+		if r.Err != nil {
+			fmt.Fprintf(sb, " (error: %v)", r.Err)
+		}
+		sb.WriteString("\n")
+
+		writeRuleResults(sb, r.Rules, depth+1)
+	}
+}
+
+func passFail(passed bool) string {
+	if passed {
+		return "pass"
+	}
+	return "fail"
+}
@@ -0,0 +1,87 @@
+package go_policy_enforcer
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDecision_String(t *testing.T) {
+	decision := Decision{
+		Allowed: false,
+		Outcome: OutcomeDeny,
+		Policies: []PolicyResult{
+			{
+				Policy: &Policy{Name: "AgePolicy"},
+				Passed: false,
+				Rules: []RuleResult{
+					{Field: "Age", Operator: ">", Expected: 25, Value: 20, Passed: false},
+					{Field: "Name", Operator: "==", Expected: "bob", Err: errors.New("field Name not found")},
+				},
+			},
+		},
+	}
+
+	expected := strings.Join([]string{
+		"denied (deny)",
+		`  policy "AgePolicy": fail`,
+		"    Age > 25: fail (got 20)",
+		"    Name == bob: fail (got <nil>) (error: field Name not found)",
+		"",
+	}, "\n")
+
+	if got := decision.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestDecision_MarshalJSON(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name: "Readings",
+			Rules: []Rule{
+				{Field: "Temperature", Operator: "<", Value: 40},
+				{Field: "Pressure", Operator: ">", Value: 0},
+				{Field: "Samples", Operator: "contains_any", Value: []float64{1}},
+			},
+		},
+	}
+
+	resource := struct {
+		Temperature float64
+		Pressure    float32
+		Samples     []float64
+	}{Temperature: math.NaN(), Pressure: float32(math.Inf(1)), Samples: []float64{math.Inf(-1)}}
+
+	encoded, err := json.Marshal(NewPolicyEnforcer(policies).Decide(resource))
+	if err != nil {
+		t.Fatalf("unexpected error encoding the decision: %v", err)
+	}
+
+	var decoded struct {
+		Policies []struct {
+			Policy struct {
+				Name string `json:"name"`
+			} `json:"policy"`
+			Rules []struct {
+				Value any `json:"value"`
+			} `json:"rules"`
+		} `json:"policies"`
+	}
+
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected error decoding %s: %v", encoded, err)
+	}
+
+	if decoded.Policies[0].Policy.Name != "Readings" {
+		t.Errorf("expected the policy to be encoded with a \"name\" key, but got %s", encoded)
+	}
+
+	for i, expected := range []string{"NaN", "+Inf", "[-Inf]"} {
+		if value := decoded.Policies[0].Rules[i].Value; value != expected {
+			t.Errorf("expected rule %d's value to be encoded as %q, but got %v", i, expected, value)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by the error-returning evaluation functions such as
+// Policy.EvaluateE and PolicyEnforcer.EnforceE. Use errors.Is to test for them;
+// they distinguish a policy that is broken from a policy that simply denied.
+var (
+	// ErrFieldNotFound is returned when a rule's field path cannot be resolved on the resource.
+	ErrFieldNotFound = errors.New("field not found")
+
+	// ErrUnexportedField is returned when a rule's field path refers to an unexported struct field.
+	ErrUnexportedField = errors.New("field is unexported")
+
+	// ErrOperatorNotSupported is returned when a rule uses an unknown operator, or an
+	// operator that cannot be applied to the values being compared.
+	ErrOperatorNotSupported = errors.New("operator not supported")
+
+	// ErrTypeMismatch is returned when a value is not of the type required for the comparison.
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrIndexOutOfRange is returned when an indexed field path, e.g. Items[3], is out of bounds.
+	ErrIndexOutOfRange = errors.New("index out of range")
+
+	// ErrInvalidRule is returned when a rule or policy is malformed, for example a
+	// condition group that sets more than one of all, any or not, or an unknown Effect.
+	ErrInvalidRule = errors.New("invalid rule")
+
+	// ErrPolicyConflict is returned when the OnlyOneApplicable combining algorithm
+	// finds more than one applicable policy.
+	ErrPolicyConflict = errors.New("policy conflict")
+
+	// ErrInvalidOperator is returned when an operator cannot be registered, because
+	// its name is empty or taken, or it has no function.
+	ErrInvalidOperator = errors.New("invalid operator")
+
+	// ErrInvalidCombiningAlgorithm is returned when a PolicyEnforcer is configured
+	// with an unknown CombiningAlgorithm.
+	ErrInvalidCombiningAlgorithm = errors.New("invalid combining algorithm")
+)
+
+// RuleError reports the policy and rule that failed to evaluate. The underlying
+// error wraps one of the sentinel errors and can be inspected with errors.Is.
+//
+// The RuleError struct has the following fields:
+// - Policy: The name of the policy containing the rule.
+// - Rule: The rule that could not be evaluated.
+// - Err: The underlying error.
+type RuleError struct {
+	Policy string
+	Rule   Rule
+	Err    error
+}
+
+// Error implements the error interface. A condition group is described by its
+// kind, "all", "any" or "not", rather than by its comparison.
+func (e *RuleError) Error() string {
+	if group := e.Rule.groupOperator(); group != "" {
+		return fmt.Sprintf("policy %q: rule '%s': %v", e.Policy, group, e.Err)
+	}
+
+	return fmt.Sprintf("policy %q: rule '%s %s %v': %v", e.Policy, e.Rule.Field, e.Rule.Operator, e.Rule.Value, e.Err)
+}
+
+// Unwrap returns the underlying error so errors.Is and errors.As can inspect it.
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// evaluationError pairs a descriptive message with one of the sentinel errors,
+// so callers get a specific message while still being able to use errors.Is.
+type evaluationError struct {
+	sentinel error
+	message  string
+}
+
+// newEvaluationError returns an error with a formatted message that unwraps to sentinel.
+func newEvaluationError(sentinel error, format string, args ...any) error {
+	return &evaluationError{
+		sentinel: sentinel,
+		message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Error implements the error interface.
+func (e *evaluationError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the sentinel error.
+func (e *evaluationError) Unwrap() error {
+	return e.sentinel
+}
@@ -1,7 +1,8 @@
 package go_policy_enforcer
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"reflect"
 
 	"github.com/kmesiab/go-policy-enforcer/internal/utils"
@@ -11,21 +12,53 @@ import (
 // and returns a boolean result based on a comparison of the two values.
 type PolicyCheckOperator[T comparable] func(T, T) bool
 
+// ContextPolicyCheckOperator is a PolicyCheckOperator that also receives the
+// context passed to EnforceContext or MatchContext, so it can use request-scoped
+// values such as the caller's identity or a clock. Evaluations that are not
+// given a context pass context.Background(). Register one with
+// OperatorRegistry.RegisterContext.
+type ContextPolicyCheckOperator func(ctx context.Context, leftVal, rightVal any) bool
+
 // evaluatePolicyCheckOperator takes a string operator, a left value, and a right value,
 // retrieves the corresponding PolicyCheckOperator function, and evaluates it with the given values.
 // Returns the result of the comparison as a boolean.
 func evaluatePolicyCheckOperator(operator string, leftVal, rightVal any) (bool, error) {
-	opFunc, err := getPolicyCheckOperator(operator)
+	op, err := DefaultOperatorRegistry.lookup(operator)
 
-	if opFunc == nil || err != nil {
-		return false, fmt.Errorf("operator '%s' is not supported", operator)
+	if op.fn == nil || err != nil {
+		return false, newEvaluationError(ErrOperatorNotSupported, "operator '%s' is not supported", operator)
 	}
 
+	return applyPolicyCheckOperator(context.Background(), operator, op, leftVal, rightVal)
+}
+
+// applyPolicyCheckOperator evaluates an already resolved operator. Pointers are
+// dereferenced, slices are handed to evaluateSliceComparison, unless the operator
+// handles them itself, and other values are coerced to comparable types, unless
+// the operator is exact, before it is called.
+func applyPolicyCheckOperator(ctx context.Context, operator string, op registeredOperator, leftVal, rightVal any) (bool, error) {
+	return applyTypedPolicyCheckOperator(ctx, operator, op, false, leftVal, rightVal)
+}
+
+// applyTypedPolicyCheckOperator evaluates an already resolved operator like
+// applyPolicyCheckOperator. With strictTypes, the values given to an operator
+// that is not exact are not coerced, and the left value must be of a kind
+// compatible with the kinds returned by operatorOptions.valueKinds, such as the
+// bounds of a "between" range, or an error wrapping ErrTypeMismatch is returned.
+func applyTypedPolicyCheckOperator(ctx context.Context, operator string, op registeredOperator, strictTypes bool, leftVal, rightVal any) (bool, error) {
 	leftVal = utils.DereferencePointer(leftVal)
 	rightVal = utils.DereferencePointer(rightVal)
 
+	if strictTypes {
+		if kinds, ok := op.valueKinds(rightVal); ok {
+			if err := checkKinds(operandKinds(leftVal), kinds); err != nil {
+				return false, err
+			}
+		}
+	}
+
 	// Handle slice comparisons
-	if reflect.TypeOf(leftVal).Kind() == reflect.Slice || reflect.TypeOf(rightVal).Kind() == reflect.Slice {
+	if !op.handlesSlices && (isSlice(leftVal) || isSlice(rightVal)) {
 		ok, err := evaluateSliceComparison[any](leftVal, rightVal, operator)
 
 		if err != nil {
@@ -36,10 +69,36 @@ func evaluatePolicyCheckOperator(operator string, leftVal, rightVal any) (bool,
 
 	}
 
-	leftVal = utils.CoerceToComparable(leftVal)
-	rightVal = utils.CoerceToComparable(rightVal)
+	if strictTypes && op.strictFn != nil {
+		return op.strictFn(ctx, leftVal, rightVal), nil
+	}
+
+	switch {
+	case !op.builtin:
+		leftVal, rightVal = userOperand(leftVal, strictTypes), userOperand(rightVal, strictTypes)
+	case !op.exact && !strictTypes:
+		leftVal = utils.CoerceToComparable(leftVal)
+		rightVal = utils.CoerceToComparable(rightVal)
+	}
+
+	return op.fn(ctx, leftVal, rightVal), nil
+}
+
+// userOperand returns v as it is given to an operator added with Register or
+// RegisterContext. Such operators are given values coerced as they always have
+// been, by utils.CoerceToBasicComparable, so numbers from JSON policies are a
+// float64 rather than a json.Number or *big.Rat. With strict types values are
+// not coerced, but numbers from JSON policies are still a float64.
+func userOperand(v any, strictTypes bool) any {
+	if !strictTypes {
+		return utils.CoerceToBasicComparable(v)
+	}
+
+	if n, ok := v.(json.Number); ok {
+		return utils.JSONNumberToFloat(n)
+	}
 
-	return opFunc(leftVal, rightVal), nil
+	return v
 }
 
 // evaluateSliceComparison compares two slices or checks if a value is within a slice based on the given operator.
@@ -75,7 +134,7 @@ func evaluateSliceComparison[T comparable](leftVal, rightVal any, operator strin
 		case "!==":
 			return !reflect.DeepEqual(leftSlice, rightSlice), nil
 		default:
-			return false, fmt.Errorf("operator '%s' is not supported for slice comparison", operator)
+			return false, newEvaluationError(ErrOperatorNotSupported, "operator '%s' is not supported for slice comparison", operator)
 		}
 	} else {
 		// Check if a value is within a slice
@@ -85,40 +144,77 @@ func evaluateSliceComparison[T comparable](leftVal, rightVal any, operator strin
 			if leftIsSlice {
 				val, ok := rightVal.(T)
 				if !ok {
-					return false, fmt.Errorf("failed to convert right value to type T")
+					return false, newEvaluationError(ErrTypeMismatch, "failed to convert right value to type T")
 				}
-				return utils.SliceContainsElement(val, leftSlice), nil
+				return sliceContainsValue(val, leftSlice), nil
 			} else if rightIsSlice {
 				val, ok := leftVal.(T)
 				if !ok {
-					return false, fmt.Errorf("failed to convert left value to type T")
+					return false, newEvaluationError(ErrTypeMismatch, "failed to convert left value to type T")
 				}
-				return utils.SliceContainsElement(val, rightSlice), nil
+				return sliceContainsValue(val, rightSlice), nil
 			}
 		case "not in":
 			if leftIsSlice {
 
 				val, ok := rightVal.(T)
 				if !ok {
-					return false, fmt.Errorf("failed to convert left value to type T")
+					return false, newEvaluationError(ErrTypeMismatch, "failed to convert left value to type T")
 				}
 
-				return !utils.SliceContainsElement(val, leftSlice), nil
+				return !sliceContainsValue(val, leftSlice), nil
 
 			} else if rightIsSlice {
 
 				val, ok := leftVal.(T)
 				if !ok {
-					return false, fmt.Errorf("failed to convert left value to type T")
+					return false, newEvaluationError(ErrTypeMismatch, "failed to convert left value to type T")
 				}
-				return !utils.SliceContainsElement(val, rightSlice), nil
+				return !sliceContainsValue(val, rightSlice), nil
 
 			}
 		default:
-			return false, fmt.Errorf("operator '%s' is not supported for value-to-slice comparison", operator)
+			return false, newEvaluationError(ErrOperatorNotSupported, "operator '%s' is not supported for value-to-slice comparison", operator)
 
 		}
 	}
 
-	return false, fmt.Errorf("invalid comparison: operator '%s' not supported for the given values", operator)
+	return false, newEvaluationError(ErrOperatorNotSupported, "invalid comparison: operator '%s' not supported for the given values", operator)
+}
+
+// sliceContainsValue reports whether haystack contains needle. Values that are
+// not identical are compared as "==" compares them with strict types, so
+// numbers of any type, including json.Number values decoded by LoadPolicy,
+// match by value and Comparable values decide for themselves.
+func sliceContainsValue[T comparable](needle T, haystack []T) bool {
+	if utils.SliceContainsElement(needle, haystack) {
+		return true
+	}
+
+	for _, elem := range haystack {
+		if strictEqualsPolicyCheckOperator(needle, elem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSlice reports whether val is a slice. A nil interface is not a slice, and
+// neither are slices that are compared as a whole, such as a net.IP, which
+// implements encoding.TextMarshaler, or a Comparable value.
+func isSlice(val any) bool {
+	t := reflect.TypeOf(val)
+	return t != nil && t.Kind() == reflect.Slice && !isCustomType(t)
+}
+
+// contextError returns ctx.Err() once ctx is done, and nil otherwise. It does not
+// block, and is cheap enough to call between rules and slice elements.
+func contextError(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
 }
@@ -0,0 +1,118 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"reflect"
+)
+
+// missingField is the value passed to operators that handle missing fields,
+// such as "exists", when a rule's field cannot be resolved.
+type missingField struct{}
+
+// existenceOperatorOptions are the options of the existence and emptiness
+// operators. They see values exactly as resolved, including slices, nil and
+// fields that are missing.
+var existenceOperatorOptions = operatorOptions{prepare: preparePredicate, exact: true, handlesSlices: true, missingFields: true}
+
+// isMissingFieldError reports whether err means the field a rule refers to is
+// not there: a struct field or map key that does not exist, a nil pointer part
+// way along the path or a slice index past the end.
+func isMissingFieldError(err error) bool {
+	return errors.Is(err, ErrFieldNotFound) || errors.Is(err, ErrIndexOutOfRange)
+}
+
+// preparePredicate validates the value of a predicate rule such as
+// "is_private", which must be a boolean. A rule without a value expects true.
+func preparePredicate(value any) (any, error) {
+	switch value.(type) {
+	case nil:
+		return true, nil
+	case bool:
+		return value, nil
+	default:
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a predicate rule must be a boolean, got %T", value)
+	}
+}
+
+// predicateExpectation returns the outcome a predicate rule expects, the
+// rule's boolean value or true when it has none. ok is false for other values.
+func predicateExpectation(rightVal any) (expected, ok bool) {
+	if rightVal == nil {
+		return true, true
+	}
+
+	expected, ok = rightVal.(bool)
+
+	return expected, ok
+}
+
+// predicatePolicyCheckOperator returns an operator that passes when test
+// returns the outcome the rule expects for the left value.
+func predicatePolicyCheckOperator(test func(v any) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		expected, ok := predicateExpectation(rightVal)
+		return ok && test(leftVal) == expected
+	}
+}
+
+// isMissing reports whether v stands for a field that could not be resolved.
+func isMissing(v any) bool {
+	_, missing := v.(missingField)
+	return missing
+}
+
+// isNil reports whether v is nil, a nil pointer, map, slice, interface,
+// function or channel, or a missing field.
+func isNil(v any) bool {
+	if v == nil || isMissing(v) {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// isEmpty reports whether v is nil, has a length of zero or is the zero value
+// of its type, such as 0, false or an empty struct, or is a missing field.
+func isEmpty(v any) bool {
+	if isNil(v) {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+// existsPolicyCheckOperator checks if the rule's field can be resolved. A field
+// that holds nil exists.
+var existsPolicyCheckOperator = predicatePolicyCheckOperator(func(v any) bool {
+	return !isMissing(v)
+})
+
+// notExistsPolicyCheckOperator checks if the rule's field cannot be resolved.
+var notExistsPolicyCheckOperator = predicatePolicyCheckOperator(isMissing)
+
+// isNilPolicyCheckOperator checks if the rule's field is nil or missing.
+var isNilPolicyCheckOperator = predicatePolicyCheckOperator(isNil)
+
+// isEmptyPolicyCheckOperator checks if the rule's field is nil, missing, empty
+// or the zero value of its type.
+var isEmptyPolicyCheckOperator = predicatePolicyCheckOperator(isEmpty)
+
+// isNotEmptyPolicyCheckOperator checks if the rule's field holds a value that
+// is not empty, as the opposite of is_empty.
+var isNotEmptyPolicyCheckOperator = predicatePolicyCheckOperator(func(v any) bool {
+	return !isEmpty(v)
+})
@@ -0,0 +1,166 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExistencePolicyCheckOperators(t *testing.T) {
+	var nilPtr *int
+	var nilMap map[string]any
+	var nilSlice []string
+	var nilErr error
+	zero := 0
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"exists", "value", nil, true},
+		{"exists", nil, nil, true}, // A field holding nil exists
+		{"exists", missingField{}, nil, false},
+		{"exists", missingField{}, false, true},
+		{"not_exists", missingField{}, nil, true},
+		{"not_exists", 0, nil, false},
+		{"is_nil", nil, nil, true},
+		{"is_nil", nilPtr, nil, true},
+		{"is_nil", nilMap, true, true},
+		{"is_nil", nilSlice, nil, true},
+		{"is_nil", nilErr, nil, true},
+		{"is_nil", missingField{}, nil, true},
+		{"is_nil", []string{}, nil, false},
+		{"is_nil", 0, nil, false},
+		{"is_nil", &zero, false, true},
+		{"is_empty", "", nil, true},
+		{"is_empty", []string{}, nil, true},
+		{"is_empty", map[string]int{}, nil, true},
+		{"is_empty", 0, nil, true},
+		{"is_empty", false, nil, true},
+		{"is_empty", time.Time{}, nil, true},
+		{"is_empty", struct{ Name string }{}, nil, true},
+		{"is_empty", &zero, nil, true},
+		{"is_empty", missingField{}, nil, true},
+		{"is_empty", " ", nil, false},
+		{"is_empty", []string{""}, nil, false},
+		{"is_empty", [2]int{}, nil, false},
+		{"is_empty", 0.5, nil, false},
+		{"is_not_empty", "value", nil, true},
+		{"is_not_empty", []int{0}, nil, true},
+		{"is_not_empty", "", nil, false},
+		{"is_not_empty", missingField{}, nil, false},
+		{"is_not_empty", "", false, true},
+		{"exists", "value", "yes", false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Existence(t *testing.T) {
+	type Manager struct {
+		Name string
+	}
+
+	type Employee struct {
+		Manager    *Manager
+		DeletedAt  *time.Time
+		Tags       []string
+		Attributes map[string]any
+	}
+
+	deletedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		employee Employee
+		expected bool
+	}{
+		{"nil pointer", Rule{Field: "DeletedAt", Operator: "is_nil"}, Employee{}, true},
+		{"set pointer", Rule{Field: "DeletedAt", Operator: "is_nil"}, Employee{DeletedAt: &deletedAt}, false},
+		{"missing map key", Rule{Field: "Attributes.legacy", Operator: "not_exists"}, Employee{Attributes: map[string]any{}}, true},
+		{"nil map", Rule{Field: "Attributes.legacy", Operator: "not_exists"}, Employee{}, true},
+		{"present map key", Rule{Field: "Attributes.legacy", Operator: "not_exists"}, Employee{Attributes: map[string]any{"legacy": nil}}, false},
+		{"nil map value", Rule{Field: "Attributes.legacy", Operator: "is_nil"}, Employee{Attributes: map[string]any{"legacy": nil}}, true},
+		{"through nil pointer", Rule{Field: "Manager.Name", Operator: "exists"}, Employee{}, false},
+		{"through set pointer", Rule{Field: "Manager.Name", Operator: "exists"}, Employee{Manager: &Manager{}}, true},
+		{"empty string through pointer", Rule{Field: "Manager.Name", Operator: "is_empty"}, Employee{Manager: &Manager{}}, true},
+		{"index past the end", Rule{Field: "Tags[1]", Operator: "exists", Value: false}, Employee{Tags: []string{"a"}}, true},
+		{"empty slice", Rule{Field: "Tags", Operator: "is_not_empty"}, Employee{Tags: []string{}}, false},
+		{"filled slice", Rule{Field: "Tags", Operator: "is_not_empty"}, Employee{Tags: []string{"a"}}, true},
+		{"unknown field", Rule{Field: "Salary", Operator: "not_exists"}, Employee{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := []Policy{{Name: "Existence", Rules: []Rule{tt.rule}}}
+
+			passed, err := policies[0].EvaluateE(tt.employee)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if passed != tt.expected {
+				t.Errorf("expected EvaluateE to return %v, but got %v", tt.expected, passed)
+			}
+
+			program, err := Compile(policies, Employee{})
+			if err != nil {
+				t.Fatalf("unexpected compile error: %v", err)
+			}
+
+			if result := program.Enforce(tt.employee); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_MissingFieldStillAnError(t *testing.T) {
+	policy := Policy{Name: "Missing", Rules: []Rule{{Field: "Salary", Operator: "==", Value: 0}}}
+
+	if _, err := policy.EvaluateE(struct{ Name string }{}); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound, but got %v", err)
+	}
+
+	// Unexported fields are not treated as missing
+	policy = Policy{Name: "Unexported", Rules: []Rule{{Field: "secret", Operator: "not_exists"}}}
+	if _, err := policy.EvaluateE(struct{ secret string }{}); !errors.Is(err, ErrUnexportedField) {
+		t.Errorf("expected ErrUnexportedField, but got %v", err)
+	}
+}
+
+func TestPolicyEnforcer_Decide_MissingField(t *testing.T) {
+	policies := []Policy{{Name: "NoLegacy", Rules: []Rule{{Field: "Attributes.legacy", Operator: "not_exists"}}}}
+
+	decision := NewPolicyEnforcer(&policies).Decide(struct{ Attributes map[string]any }{})
+	if !decision.Allowed || decision.Err != nil {
+		t.Fatalf("expected the resource to be allowed, but got %+v", decision)
+	}
+
+	expected := "Attributes.legacy not_exists <nil>: pass (got <nil>)"
+	if !strings.Contains(decision.String(), expected) {
+		t.Errorf("expected the explanation to contain %q, but got:\n%s", expected, decision.String())
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidPredicate(t *testing.T) {
+	policy := Policy{Name: "Predicate", Rules: []Rule{{Field: "Name", Operator: "exists", Value: "yes"}}}
+
+	if _, err := policy.EvaluateE(struct{ Name string }{}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule, but got %v", err)
+	}
+}
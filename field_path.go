@@ -0,0 +1,227 @@
+package go_policy_enforcer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldPath is a parsed rule field path such as "Items[0].Labels.team". Parsing
+// happens once per rule, and when the path is bound to a resource type the
+// struct field indexes are cached so resolving it avoids FieldByName lookups.
+//
+// A fieldPath is not modified after it has been bound, so a bound path can be
+// resolved from multiple goroutines at once.
+type fieldPath struct {
+	raw      string
+	segments []pathSegment
+
+	// bound caches the location of each segment's struct field once the path
+	// has been bound to a type. It is nil for paths that have not been bound.
+	bound []boundSegment
+}
+
+// pathSegment is a single dot separated part of a fieldPath.
+type pathSegment struct {
+	// raw is the segment as written, used as the key when indexing a map
+	raw string
+
+	// name is the struct field name, without any [index] suffix
+	name string
+
+	// indexed is true for segments such as Items[0]
+	indexed  bool
+	index    int
+	indexErr error
+}
+
+// boundSegment is the location of a segment's struct field within structType.
+// It is only used when the value being resolved has exactly this type.
+type boundSegment struct {
+	structType reflect.Type
+	fieldIndex []int
+}
+
+// parseFieldPath splits a dot separated field path into segments.
+func parseFieldPath(path string) *fieldPath {
+	parts := strings.Split(path, ".")
+	p := &fieldPath{raw: path, segments: make([]pathSegment, len(parts))}
+
+	for i, part := range parts {
+		seg := pathSegment{raw: part, name: part}
+
+		// Handle slice indexing (e.g., Field[0])
+		if strings.Contains(part, "[") && strings.Contains(part, "]") {
+			split := strings.Split(part, "[")
+			indexStr := strings.TrimSuffix(split[1], "]")
+
+			seg.indexed = true
+			seg.name = split[0]
+
+			var err error
+			if seg.index, err = strconv.Atoi(indexStr); err != nil {
+				seg.indexErr = newEvaluationError(ErrFieldNotFound, "invalid slice index %s", indexStr)
+			}
+		}
+
+		p.segments[i] = seg
+	}
+
+	return p
+}
+
+// resolve walks the path on v. Struct fields, map keys and slice or array
+// indexes are supported, and pointers and interfaces are followed between
+// segments.
+//
+// Errors wrap ErrFieldNotFound, ErrUnexportedField, ErrTypeMismatch or
+// ErrIndexOutOfRange.
+func (p *fieldPath) resolve(v reflect.Value) (reflect.Value, error) {
+	for i := range p.segments {
+		seg := &p.segments[i]
+
+		// Follow pointers and interfaces, such as the values of a map[string]any
+		for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+			v = v.Elem()
+		}
+
+		// Handle map access
+		if v.Kind() == reflect.Map {
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, newEvaluationError(ErrTypeMismatch, "map keys for %s are not strings", seg.raw)
+			}
+
+			key := reflect.ValueOf(seg.raw).Convert(v.Type().Key())
+			v = v.MapIndex(key)
+			if !v.IsValid() {
+				return reflect.Value{}, newEvaluationError(ErrFieldNotFound, "key %s not found in map", seg.raw)
+			}
+		} else if seg.indexed {
+			// Get the field by name
+			v = p.field(i, v)
+			if !v.IsValid() {
+				return reflect.Value{}, newEvaluationError(ErrFieldNotFound, "field %s not found", seg.name)
+			}
+
+			// Ensure it's a slice or array
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return reflect.Value{}, newEvaluationError(ErrTypeMismatch, "field %s is not a slice or array", seg.name)
+			}
+
+			if seg.indexErr != nil {
+				return reflect.Value{}, seg.indexErr
+			}
+
+			// Check if the index is within bounds
+			if seg.index < 0 || seg.index >= v.Len() {
+				return reflect.Value{}, newEvaluationError(ErrIndexOutOfRange, "index %d out of bounds for slice %s", seg.index, seg.name)
+			}
+
+			// Get the indexed value
+			v = v.Index(seg.index)
+		} else {
+			// Regular struct field access
+			v = p.field(i, v)
+			if !v.IsValid() {
+				return reflect.Value{}, newEvaluationError(ErrFieldNotFound, "field %s not found", seg.name)
+			}
+		}
+
+		// Check if the field is exported (CanInterface returns false for unexported fields)
+		if !v.CanInterface() {
+			return reflect.Value{}, newEvaluationError(ErrUnexportedField, "field %s is unexported and cannot be accessed", seg.name)
+		}
+	}
+
+	return v, nil
+}
+
+// field returns the struct field named by segment i, or the zero Value when
+// v is not a struct (for example a nil pointer part way along a path).
+func (p *fieldPath) field(i int, v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	// Use the cached field index when the path was bound to this type
+	if p.bound != nil && p.bound[i].structType == v.Type() {
+		field, err := v.FieldByIndexErr(p.bound[i].fieldIndex)
+		if err != nil {
+			return reflect.Value{}
+		}
+		return field
+	}
+
+	return v.FieldByName(p.segments[i].name)
+}
+
+// bind caches the struct field indexes for each segment, starting from t, and
+// returns the static type the path resolves to. Binding stops, returning a nil
+// type, as soon as a segment's type is only known at runtime, for example an
+// interface or a map value.
+//
+// An error is returned when t proves the path can never resolve: a field that
+// does not exist, an unexported field, or indexing into a non slice.
+func (p *fieldPath) bind(t reflect.Type) (reflect.Type, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	p.bound = make([]boundSegment, len(p.segments))
+
+	for i := range p.segments {
+		if t == nil {
+			return nil, nil
+		}
+
+		seg := &p.segments[i]
+
+		switch t.Kind() {
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return nil, newEvaluationError(ErrTypeMismatch, "map keys for %s are not strings", seg.raw)
+			}
+			t = t.Elem()
+
+		case reflect.Struct:
+			field, ok := t.FieldByName(seg.name)
+			if !ok {
+				return nil, newEvaluationError(ErrFieldNotFound, "field %s not found", seg.name)
+			}
+
+			if !field.IsExported() {
+				return nil, newEvaluationError(ErrUnexportedField, "field %s is unexported and cannot be accessed", seg.name)
+			}
+
+			p.bound[i] = boundSegment{structType: t, fieldIndex: field.Index}
+			t = field.Type
+
+			if seg.indexed {
+				if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+					return nil, newEvaluationError(ErrTypeMismatch, "field %s is not a slice or array", seg.name)
+				}
+
+				if seg.indexErr != nil {
+					return nil, seg.indexErr
+				}
+
+				t = t.Elem()
+			}
+
+		default:
+			// Interfaces and other kinds are only known at runtime
+			return nil, nil
+		}
+
+		// Pointers are followed between segments
+		if t.Kind() == reflect.Ptr && i < len(p.segments)-1 {
+			t = t.Elem()
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,53 @@
+package go_policy_enforcer
+
+// fieldRefKey is the key of the JSON object that marks a rule value as a FieldRef.
+const fieldRefKey = "$field"
+
+// FieldRef is a rule value that refers to another field of the resource, so a
+// rule can compare two fields, such as "EndDate > StartDate". The field path is
+// resolved the same way as Rule.Field, so nested, indexed and map paths work.
+// Within nested rules, both paths are relative to the slice element.
+//
+// In JSON a FieldRef is written as {"$field": "StartDate"}.
+//
+// The FieldRef struct has the following fields:
+// - Field: The dot separated path of the field to compare against.
+type FieldRef struct {
+	Field string `json:"$field"`
+}
+
+// asFieldRef reports whether a rule value refers to a field, either as a
+// FieldRef, a *FieldRef or the decoded JSON form {"$field": "path"}. An error is
+// returned for a reference that is malformed.
+func asFieldRef(value any) (FieldRef, bool, error) {
+	var ref FieldRef
+
+	switch v := value.(type) {
+	case FieldRef:
+		ref = v
+	case *FieldRef:
+		if v == nil {
+			return FieldRef{}, false, nil
+		}
+		ref = *v
+	case map[string]any:
+		field, ok := v[fieldRefKey]
+		if !ok {
+			return FieldRef{}, false, nil
+		}
+
+		path, isString := field.(string)
+		if len(v) != 1 || !isString {
+			return FieldRef{}, true, newEvaluationError(ErrInvalidRule, "a field reference must be an object with a single string %q key", fieldRefKey)
+		}
+		ref.Field = path
+	default:
+		return FieldRef{}, false, nil
+	}
+
+	if ref.Field == "" {
+		return FieldRef{}, true, newEvaluationError(ErrInvalidRule, "a field reference must name a field")
+	}
+
+	return ref, true, nil
+}
@@ -0,0 +1,200 @@
+package go_policy_enforcer
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fieldRefTestLine struct {
+	Price int
+	Cost  int
+}
+
+type fieldRefTestApproval struct {
+	By string
+}
+
+type fieldRefTestOrder struct {
+	StartDate  int
+	EndDate    int
+	CreatedBy  string
+	ApprovedBy string
+	Approvals  []fieldRefTestApproval
+	Limits     map[string]int
+	Total      int
+	Lines      []fieldRefTestLine
+	Discount   *int
+}
+
+func TestPolicy_Evaluate_FieldRef(t *testing.T) {
+	order := fieldRefTestOrder{
+		StartDate:  10,
+		EndDate:    20,
+		CreatedBy:  "alice",
+		ApprovedBy: "bob",
+		Approvals:  []fieldRefTestApproval{{By: "bob"}, {By: "carol"}},
+		Limits:     map[string]int{"max": 100},
+		Total:      80,
+		Lines:      []fieldRefTestLine{{Price: 5, Cost: 8}, {Price: 12, Cost: 10}},
+	}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected bool
+	}{
+		{"greater than", Rule{Field: "EndDate", Operator: ">", Value: FieldRef{Field: "StartDate"}}, true},
+		{"less than", Rule{Field: "EndDate", Operator: "<", Value: FieldRef{Field: "StartDate"}}, false},
+		{"not equal", Rule{Field: "ApprovedBy", Operator: "!=", Value: FieldRef{Field: "CreatedBy"}}, true},
+		{"pointer", Rule{Field: "ApprovedBy", Operator: "==", Value: &FieldRef{Field: "CreatedBy"}}, false},
+		{"indexed path", Rule{Field: "ApprovedBy", Operator: "==", Value: FieldRef{Field: "Approvals[0].By"}}, true},
+		{"map path", Rule{Field: "Total", Operator: "<=", Value: FieldRef{Field: "Limits.max"}}, true},
+		{"json form", Rule{Field: "EndDate", Operator: ">", Value: map[string]any{"$field": "StartDate"}}, true},
+		{
+			"relative to nested element",
+			Rule{Field: "Lines", Operator: "any", Value: []Rule{
+				{Field: "Price", Operator: ">", Value: FieldRef{Field: "Cost"}},
+			}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "FieldRefPolicy", Rules: []Rule{tt.rule}}
+
+			ok, err := policy.EvaluateE(order)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ok != tt.expected {
+				t.Errorf("expected EvaluateE to return %v, but got %v", tt.expected, ok)
+			}
+
+			program, err := Compile([]Policy{policy}, fieldRefTestOrder{})
+			if err != nil {
+				t.Fatalf("unexpected compile error: %v", err)
+			}
+
+			if result := program.Enforce(order); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_FieldRefErrors(t *testing.T) {
+	order := fieldRefTestOrder{Approvals: []fieldRefTestApproval{}}
+
+	tests := []struct {
+		name     string
+		value    any
+		expected error
+	}{
+		{"missing field", FieldRef{Field: "Finished"}, ErrFieldNotFound},
+		{"index out of range", FieldRef{Field: "Approvals[3].By"}, ErrIndexOutOfRange},
+		{"nil pointer path", FieldRef{Field: "Discount.Value"}, ErrFieldNotFound},
+		{"empty field", FieldRef{}, ErrInvalidRule},
+		{"non string json field", map[string]any{"$field": 1}, ErrInvalidRule},
+		{"extra json keys", map[string]any{"$field": "StartDate", "default": 0}, ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "FieldRefPolicy", Rules: []Rule{{Field: "EndDate", Operator: ">", Value: tt.value}}}
+
+			if _, err := policy.EvaluateE(order); !errors.Is(err, tt.expected) {
+				t.Errorf("expected error wrapping %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCompile_FieldRefErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected error
+	}{
+		{"missing field", FieldRef{Field: "Finished"}, ErrFieldNotFound},
+		{"missing nested field", FieldRef{Field: "Approvals[0].Name"}, ErrFieldNotFound},
+		{"malformed", map[string]any{"$field": true}, ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := []Policy{{Name: "p", Rules: []Rule{{Field: "EndDate", Operator: ">", Value: tt.value}}}}
+
+			if _, err := Compile(policies, fieldRefTestOrder{}); !errors.Is(err, tt.expected) {
+				t.Errorf("expected error wrapping %v, but got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy_FieldRef(t *testing.T) {
+	policyJSON := `{
+		"Name": "ValidPeriod",
+		"Rules": [
+			{"field": "EndDate", "operator": ">", "value": {"$field": "StartDate"}}
+		]
+	}`
+
+	var policy Policy
+	if err := json.Unmarshal([]byte(policyJSON), &policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !policy.Evaluate(fieldRefTestOrder{StartDate: 1, EndDate: 2}) {
+		t.Errorf("expected Evaluate to return true, but got false")
+	}
+
+	if policy.Evaluate(fieldRefTestOrder{StartDate: 2, EndDate: 1}) {
+		t.Errorf("expected Evaluate to return false, but got true")
+	}
+
+	// A FieldRef written from Go round trips through JSON
+	encoded, err := json.Marshal(Rule{Field: "EndDate", Operator: ">", Value: FieldRef{Field: "StartDate"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(string(encoded), `"value":{"$field":"StartDate"}`) {
+		t.Errorf("unexpected JSON: %s", encoded)
+	}
+}
+
+func TestPolicyEnforcer_Decide_FieldRef(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name: "ValidPeriod",
+			Rules: []Rule{
+				{Field: "EndDate", Operator: ">", Value: FieldRef{Field: "StartDate"}},
+				{Field: "Lines", Operator: "any", Value: []Rule{
+					{Field: "Price", Operator: ">", Value: FieldRef{Field: "Cost"}},
+				}},
+			},
+		},
+	}
+
+	order := fieldRefTestOrder{StartDate: 30, EndDate: 20, Lines: []fieldRefTestLine{{Price: 5, Cost: 8}}}
+
+	decision := NewPolicyEnforcer(policies).Decide(order)
+
+	rules := decision.Policies[0].Rules
+	if rules[0].Expected != 30 || rules[0].ExpectedField != "StartDate" {
+		t.Errorf("expected the referenced value to be recorded, but got %+v", rules[0])
+	}
+
+	if nested := rules[1].Rules[0]; nested.ExpectedField != "Lines[0].Cost" {
+		t.Errorf("expected the nested reference to be indexed, but got %q", nested.ExpectedField)
+	}
+
+	expected := "    EndDate > StartDate: fail (got 20, StartDate is 30)\n"
+	if s := decision.String(); !strings.Contains(s, expected) {
+		t.Errorf("expected the explanation to contain %q, but got:\n%s", expected, s)
+	}
+}
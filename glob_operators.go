@@ -0,0 +1,175 @@
+package go_policy_enforcer
+
+import (
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// globSeparator separates the segments of the names matched by glob patterns.
+const globSeparator = "/"
+
+// globPattern is a rule value that has been split into segments and validated.
+type globPattern struct {
+	segments []string
+}
+
+// globPatterns is the prepared value of a "glob_any" rule. It is a struct so
+// that it is not mistaken for a list of values.
+type globPatterns struct {
+	patterns []globPattern
+}
+
+// parseGlob splits a glob pattern into segments and checks that each one is
+// well formed.
+func parseGlob(expr string) (globPattern, error) {
+	segments := strings.Split(expr, globSeparator)
+
+	for _, segment := range segments {
+		if segment == "**" {
+			continue
+		}
+
+		if _, err := path.Match(segment, ""); err != nil {
+			return globPattern{}, newEvaluationError(ErrInvalidRule, "invalid glob pattern %q: %v", expr, err)
+		}
+	}
+
+	return globPattern{segments: segments}, nil
+}
+
+// match reports whether name matches the pattern. Each segment is matched with
+// path.Match, except "**", which matches any number of whole segments.
+func (g globPattern) match(name string) bool {
+	return matchGlobSegments(g.segments, strings.Split(name, globSeparator))
+}
+
+// matchGlobSegments matches the segments of a name against the segments of a
+// pattern.
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+
+			if len(pattern) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// prepareGlob validates the value of a "glob" rule, which must be a pattern.
+func prepareGlob(value any) (any, error) {
+	expr, ok := value.(string)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a glob rule must be a string, got %T", value)
+	}
+
+	return parseGlob(expr)
+}
+
+// prepareGlobList validates the value of a "glob_any" rule, which must be a
+// list of patterns.
+func prepareGlobList(value any) (any, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a glob_any rule must be a list of strings, got %T", value)
+	}
+
+	patterns := make([]globPattern, 0, v.Len())
+
+	for i := 0; i < v.Len(); i++ {
+		expr, ok := utils.DereferencePointer(v.Index(i).Interface()).(string)
+		if !ok {
+			return nil, newEvaluationError(ErrInvalidRule, "the value of a glob_any rule must be a list of strings, got %T at index %d", v.Index(i).Interface(), i)
+		}
+
+		pattern, err := parseGlob(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return globPatterns{patterns: patterns}, nil
+}
+
+// globPolicyCheckOperator checks if the left value, a string, matches the glob
+// pattern in the right value. When the left value is a slice of strings, it
+// checks if any element matches.
+//
+// Patterns are matched segment by segment, where segments are separated by "/".
+// Within a segment, "*" matches any run of characters, "?" matches a single
+// character and "[a-z]" matches a character class, as in path.Match. A segment
+// that is exactly "**" matches any number of segments, including none.
+var globPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	var pattern globPattern
+
+	switch right := rightVal.(type) {
+	case globPattern:
+		pattern = right
+	case string:
+		var err error
+		if pattern, err = parseGlob(right); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	matched, ok := anyString(leftVal, pattern.match)
+
+	return ok && matched
+}
+
+// globAnyPolicyCheckOperator checks if the left value, a string, matches any of
+// the glob patterns in the right value, a list of patterns. See
+// globPolicyCheckOperator for the pattern syntax.
+var globAnyPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	patterns, ok := rightVal.(globPatterns)
+	if !ok {
+		prepared, err := prepareGlobList(rightVal)
+		if err != nil {
+			return false
+		}
+
+		patterns = prepared.(globPatterns)
+	}
+
+	matched, ok := anyString(leftVal, func(name string) bool {
+		for _, pattern := range patterns.patterns {
+			if pattern.match(name) {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return ok && matched
+}
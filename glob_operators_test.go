@@ -0,0 +1,153 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGlobPolicyCheckOperator(t *testing.T) {
+	name := "projects/x/buckets/y"
+
+	tests := []struct {
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"projects/x/buckets/y", "projects/x/buckets/y", true},
+		{"projects/x/buckets/y", "projects/*/buckets/*", true},
+		{"projects/x/buckets/y", "projects/*", false}, // * does not cross segments
+		{"projects/x/buckets/y", "projects/?/buckets/[a-z]", true},
+		{"projects/xy/buckets/y", "projects/?/buckets/*", false},
+		{"projects/x/buckets/y", "projects/[^x]/buckets/*", false},
+		{"projects/x/buckets/y/objects/z", "projects/**", true},
+		{"projects/x/buckets/y/objects/z", "projects/**/objects/*", true},
+		{"projects/x/objects/z", "projects/x/**/objects/z", true}, // ** matches no segments
+		{"projects/x/buckets/y/objects/z", "**/objects/z", true},
+		{"projects/x/buckets/y/objects/z", "projects/**/buckets", false},
+		{"projects/x/buckets/y/objects/z", "**/**/z", true},
+		{"projects/x", "**", true},
+		{"projects/x.log", "projects/*.log", true},
+		{&name, "projects/*/buckets/y", true},
+		{[]string{"projects/a", "projects/x/buckets/y"}, "projects/*/buckets/*", true},
+		{[]string{"projects/a", "projects/b"}, "projects/*/buckets/*", false},
+		{emailAddress("projects/x/buckets/y"), "projects/*/buckets/*", true},
+		{"projects/x", "projects/[", false},
+		{42, "*", false},
+		{"projects/x", 42, false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator("glob", tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v glob %v: %v", tt.leftVal, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v glob %v = %v; want %v", tt.leftVal, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestGlobAnyPolicyCheckOperator(t *testing.T) {
+	tests := []struct {
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"projects/x/buckets/y", []string{"projects/a/**", "projects/x/**"}, true},
+		{"projects/x/buckets/y", []any{"projects/a/**", "projects/b/**"}, false},
+		{"projects/x/buckets/y", []string{}, false},
+		{[]string{"projects/b/buckets/y", "projects/x"}, []string{"projects/x"}, true},
+		{"projects/x", "projects/x", false},
+		{"projects/x", []any{"projects/x", 1}, false},
+		{"projects/x", []string{"projects/["}, false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator("glob_any", tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v glob_any %v: %v", tt.leftVal, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v glob_any %v = %v; want %v", tt.leftVal, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Glob(t *testing.T) {
+	type Object struct {
+		Name string
+	}
+
+	policies := []Policy{
+		{
+			Name: "PublicObjects",
+			Rules: []Rule{
+				{Field: "Name", Operator: "glob_any", Value: []any{"projects/*/buckets/public-*/**", "projects/docs/**"}},
+				{Not: &Rule{Field: "Name", Operator: "glob", Value: "**/*.key"}},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Object{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"projects/x/buckets/public-assets/objects/logo.png", true},
+		{"projects/docs/guide.md", true},
+		{"projects/x/buckets/private/objects/logo.png", false},
+		{"projects/x/buckets/public-assets/objects/server.key", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object := Object{Name: tt.name}
+
+			if result := policies[0].Evaluate(object); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(object); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidGlob(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{"malformed pattern", "glob", "projects/[a-"},
+		{"non string pattern", "glob", 42},
+		{"pattern list as glob", "glob", []string{"projects/*"}},
+		{"single pattern as glob_any", "glob_any", "projects/*"},
+		{"malformed pattern in list", "glob_any", []string{"projects/*", "projects/["}},
+		{"non string pattern in list", "glob_any", []any{"projects/*", 42}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Glob", Rules: []Rule{{Field: "Name", Operator: tt.operator, Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ Name string }{Name: "projects/x"})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ Name string }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
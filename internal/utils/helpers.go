@@ -1,9 +1,15 @@
 package utils
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // SlicesContainSameElements checks if two slices contain the same elements, regardless of their order.
@@ -170,6 +176,11 @@ func ToMap(val any) (map[string]interface{}, bool) {
 func DereferencePointer(val any) any {
 	v := reflect.ValueOf(val)
 
+	// An untyped nil has nothing to dereference
+	if !v.IsValid() {
+		return nil
+	}
+
 	// Handle pointers to interfaces safely
 	for v.Kind() == reflect.Ptr && !v.IsNil() {
 		v = v.Elem()
@@ -179,22 +190,65 @@ func DereferencePointer(val any) any {
 	return v.Interface()
 }
 
+// ValueAs returns val as the interface type T when val, or a pointer to a copy
+// of it, implements T. Values reach the operators dereferenced, so this finds
+// methods declared on pointer receivers too. Nil pointers are treated as nil
+// and never implement T, as calling their methods could panic.
+//
+// Parameters:
+// - val: The input value of any type.
+//
+// Returns:
+// - T: val, or a pointer to a copy of it, as a T.
+// - bool: true if val or a pointer to it implements T.
+func ValueAs[T any](val any) (T, bool) {
+	var zero T
+
+	v := reflect.ValueOf(val)
+
+	// A nil *time.Time implements encoding.TextMarshaler, but panics when used
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return zero, false
+	}
+
+	if t, ok := val.(T); ok {
+		return t, true
+	}
+
+	// Only named types declared in a package can have methods
+	if !v.IsValid() || v.Kind() == reflect.Ptr || v.Type().PkgPath() == "" {
+		return zero, false
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+
+	t, ok := ptr.Interface().(T)
+	return t, ok
+}
+
 // CoerceToComparable takes a value of any type and attempts to coerce it into a comparable type.
-// It supports converting strings to integers or floats, returning the original string if conversion fails.
-// If the input value is already an integer or float64, it is returned as is.
+// It supports converting strings and json.Number values to numbers, returning the original string if conversion fails.
+// Whole numbers become an int and other numbers a *big.Rat, so decimals such as
+// "19.99" are compared exactly rather than rounded to a float64. Strings that
+// only parse as a float, such as "NaN", become a float64.
+// If the input value is already an integer or float of any type, including a
+// named type such as "type Cents int64", or a big.Int, big.Rat or big.Float, it is returned as is.
+// time.Time and time.Duration values are also returned as is, so that they are
+// compared chronologically rather than as formatted strings.
+// Values with a ComparePolicyValue method are returned as is, and values
+// implementing encoding.TextMarshaler or fmt.Stringer become their text.
 // For unsupported types, the function returns a string representation of the value.
 func CoerceToComparable(val any) any {
 	switch v := val.(type) {
-	case string:
-		// Try to convert string to int or float
-		if intValue, err := strconv.Atoi(v); err == nil {
-			return intValue
-		}
-		if floatValue, err := strconv.ParseFloat(v, 64); err == nil {
-			return floatValue
-		}
-		// If the string cannot be converted, return it as-is
+	case time.Time, time.Duration:
+		return v
+	case big.Int, big.Rat, big.Float, *big.Int, *big.Rat, *big.Float:
 		return v
+	case json.Number:
+		return coerceNumericString(string(v))
+	case string:
+		return coerceNumericString(v)
 	case int, int8, int16, int32, int64:
 		// If it's an integer type, return it as is
 		return v
@@ -205,11 +259,99 @@ func CoerceToComparable(val any) any {
 		// If it's a float type, return it as is
 		return v
 	default:
+		// Named numeric types such as "type Cents int64" are returned as is
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			return v
+		}
+
+		// Values that compare themselves with ComparePolicyValue are returned as is
+		if _, ok := ValueAs[policyValueComparer](v); ok {
+			return v
+		}
+
+		// Values that can describe themselves are compared as their text
+		if m, ok := ValueAs[encoding.TextMarshaler](v); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+
+		if s, ok := ValueAs[fmt.Stringer](v); ok {
+			return s.String()
+		}
+
 		// If it's any other type, return it as-is
 		return fmt.Sprintf("%v", v) // convert other types to string for comparison
 	}
 }
 
+// CoerceToBasicComparable coerces a value as CoerceToComparable does, except
+// that numbers are not kept exact: numeric strings become an int when they are
+// whole numbers and a float64 otherwise, and json.Number values become a
+// float64, as encoding/json decodes numbers by default. It prepares values for
+// operators added by users, which have always been given numbers this way.
+func CoerceToBasicComparable(val any) any {
+	switch v := val.(type) {
+	case json.Number:
+		return JSONNumberToFloat(v)
+	case string:
+		if intValue, err := strconv.Atoi(v); err == nil {
+			return intValue
+		}
+
+		if floatValue, err := strconv.ParseFloat(v, 64); err == nil {
+			return floatValue
+		}
+
+		return v
+	default:
+		return CoerceToComparable(v)
+	}
+}
+
+// JSONNumberToFloat returns n as a float64, as encoding/json decodes numbers by
+// default, or as a string when it is not a valid number.
+func JSONNumberToFloat(n json.Number) any {
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+
+	return string(n)
+}
+
+// policyValueComparer matches the Comparable interface of the policy package,
+// which cannot be imported here.
+type policyValueComparer interface {
+	ComparePolicyValue(other any) (int, error)
+}
+
+// coerceNumericString converts a numeric string to an int, a *big.Rat holding
+// its exact decimal value, or a float64, and returns any other string as is.
+func coerceNumericString(s string) any {
+	// Try to convert string to int or float
+	if intValue, err := strconv.Atoi(s); err == nil {
+		return intValue
+	}
+
+	floatValue, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		// If the string cannot be converted, return it as-is
+		return s
+	}
+
+	// Keep every digit of finite decimals, such as amounts of money
+	if !math.IsNaN(floatValue) && !math.IsInf(floatValue, 0) && !strings.Contains(s, "/") {
+		if ratValue, ok := new(big.Rat).SetString(s); ok {
+			return ratValue
+		}
+	}
+
+	return floatValue
+}
+
 // Len is a generic function that calculates the length of a given value.
 // It supports arrays, slices, strings, and maps. For other types, it returns 0.
 //
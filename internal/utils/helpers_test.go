@@ -1,6 +1,13 @@
 package utils
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
 
 func TestSlicesContainSameElementsDifferentLengths(t *testing.T) {
 	slice1 := []int{1, 2, 3}
@@ -101,3 +108,117 @@ func TestSlicesContainSameElementsLargeNumbers(t *testing.T) {
 		t.Errorf("Expected SlicesContainSameElements to return true for large numbers, but got false")
 	}
 }
+
+func TestDereferencePointer_UntypedNil(t *testing.T) {
+	if result := DereferencePointer(nil); result != nil {
+		t.Errorf("Expected nil, but got %v", result)
+	}
+}
+
+func TestCoerceToComparable_NamedNumericTypes(t *testing.T) {
+	type Cents int64
+	type Ratio float32
+
+	for _, val := range []any{Cents(500), Ratio(0.5), uint32(7), int8(-1)} {
+		if result := CoerceToComparable(val); result != val {
+			t.Errorf("Expected %v (%T) to be returned as is, but got %v (%T)", val, val, result, result)
+		}
+	}
+}
+
+func TestCoerceToComparable_Decimals(t *testing.T) {
+	tests := []struct {
+		val      any
+		expected any
+	}{
+		{"42", 42},
+		{json.Number("42"), 42},
+		{"19.99", big.NewRat(1999, 100)},
+		{json.Number("1e-2"), big.NewRat(1, 100)},
+		{"1e400", "1e400"}, // Out of the float64 range
+		{"1/2", "1/2"},
+		{"abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		result := CoerceToComparable(tt.val)
+
+		if rat, ok := tt.expected.(*big.Rat); ok {
+			if r, ok := result.(*big.Rat); !ok || r.Cmp(rat) != 0 {
+				t.Errorf("Expected %v to be coerced to %v, but got %v (%T)", tt.val, rat, result, result)
+			}
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("Expected %v to be coerced to %v (%T), but got %v (%T)", tt.val, tt.expected, tt.expected, result, result)
+		}
+	}
+
+	rat := big.NewRat(1, 3)
+	if result := CoerceToComparable(rat); result != rat {
+		t.Errorf("Expected %v to be returned as is, but got %v", rat, result)
+	}
+}
+
+type pointerStringer struct{ name string }
+
+func (p *pointerStringer) String() string { return "pointer " + p.name }
+
+type policyID struct{ n int }
+
+func (id policyID) ComparePolicyValue(other any) (int, error) { return 0, nil }
+
+func TestValueAs(t *testing.T) {
+	if s, ok := ValueAs[fmt.Stringer](pointerStringer{"a"}); !ok || s.String() != "pointer a" {
+		t.Errorf("Expected a value with a pointer receiver method to be found, but got %v, %v", s, ok)
+	}
+
+	if _, ok := ValueAs[fmt.Stringer](&pointerStringer{"a"}); !ok {
+		t.Error("Expected a pointer to be found")
+	}
+
+	for _, val := range []any{nil, "a", 1, struct{}{}, (*pointerStringer)(nil), (*time.Time)(nil)} {
+		if _, ok := ValueAs[fmt.Stringer](val); ok {
+			t.Errorf("Expected %v (%T) not to be a fmt.Stringer", val, val)
+		}
+	}
+}
+
+func TestCoerceToBasicComparable(t *testing.T) {
+	tests := []struct {
+		val      any
+		expected any
+	}{
+		{"42", 42},
+		{"19.99", 19.99},
+		{"1e3", 1000.0},
+		{json.Number("42"), 42.0}, // As encoding/json decodes numbers
+		{json.Number("19.99"), 19.99},
+		{json.Number("abc"), "abc"},
+		{"1e400", "1e400"},
+		{"abc", "abc"},
+		{int64(7), int64(7)},
+	}
+
+	for _, tt := range tests {
+		if result := CoerceToBasicComparable(tt.val); result != tt.expected {
+			t.Errorf("Expected %v to be coerced to %v (%T), but got %v (%T)", tt.val, tt.expected, tt.expected, result, result)
+		}
+	}
+}
+
+func TestCoerceToComparable_CustomTypes(t *testing.T) {
+	if result := CoerceToComparable(pointerStringer{"a"}); result != "pointer a" {
+		t.Errorf("Expected a fmt.Stringer to be coerced to its text, but got %v", result)
+	}
+
+	ip := net.ParseIP("10.0.0.1")
+	if result := CoerceToComparable(ip); result != "10.0.0.1" {
+		t.Errorf("Expected an encoding.TextMarshaler to be coerced to its text, but got %v", result)
+	}
+
+	if result := CoerceToComparable(policyID{7}); result != (policyID{7}) {
+		t.Errorf("Expected a value with a ComparePolicyValue method to be returned as is, but got %v (%T)", result, result)
+	}
+}
@@ -0,0 +1,124 @@
+package go_policy_enforcer
+
+import (
+	"cmp"
+	"math"
+	"math/big"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// lengthOf returns the number of elements in a slice, array or map, or the
+// number of runes in a string. ok is false for values of any other kind.
+func lengthOf(v any) (n int, ok bool) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(rv.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return utils.Len(v), true
+	default:
+		return 0, false
+	}
+}
+
+// toLength returns v as a length: a whole, non-negative number.
+func toLength(v any) (int, bool) {
+	n, ok := toNumber(v)
+	if !ok {
+		return 0, false
+	}
+
+	switch length := n.(type) {
+	case int64:
+		return int(length), length >= 0
+	case uint64:
+		return int(length), length <= math.MaxInt
+	case *big.Rat:
+		if !length.IsInt() || !length.Num().IsInt64() {
+			return 0, false
+		}
+		return toLength(length.Num().Int64())
+	default:
+		f := length.(float64)
+		return int(f), f >= 0 && f <= math.MaxInt && f == math.Trunc(f)
+	}
+}
+
+// prepareLength validates the value of a length comparison rule, which must be
+// a whole, non-negative number.
+func prepareLength(value any) (any, error) {
+	n, ok := toLength(value)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a length rule must be a non-negative whole number, got %v", value)
+	}
+
+	return n, nil
+}
+
+// prepareLengthRange validates the value of a "len_between" rule, which must be
+// a Range of lengths.
+func prepareLengthRange(value any) (any, error) {
+	prepared, err := prepareRange(value)
+	if err != nil {
+		return nil, err
+	}
+
+	r := prepared.(Range)
+
+	for _, bound := range []any{r.Min, r.Max} {
+		if _, ok := toLength(bound); !ok {
+			return nil, newEvaluationError(ErrInvalidRule, "the bounds of a len_between rule must be non-negative whole numbers, got %v", bound)
+		}
+	}
+
+	return r, nil
+}
+
+// lengthPolicyCheckOperator returns an operator that compares the length of the
+// left value with the length in the right value and passes when test accepts
+// the result, -1, 0 or +1. It fails when the left value has no length.
+func lengthPolicyCheckOperator(test func(c int) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		n, ok := lengthOf(leftVal)
+		if !ok {
+			return false
+		}
+
+		expected, ok := toLength(rightVal)
+		if !ok {
+			return false
+		}
+
+		return test(cmp.Compare(n, expected))
+	}
+}
+
+// lenEqualsPolicyCheckOperator checks if the length of the left value equals the right value.
+var lenEqualsPolicyCheckOperator = lengthPolicyCheckOperator(func(c int) bool { return c == 0 })
+
+// lenGreaterThanPolicyCheckOperator checks if the length of the left value is greater than the right value.
+var lenGreaterThanPolicyCheckOperator = lengthPolicyCheckOperator(func(c int) bool { return c > 0 })
+
+// lenGreaterThanOrEqualsPolicyCheckOperator checks if the length of the left value is at least the right value.
+var lenGreaterThanOrEqualsPolicyCheckOperator = lengthPolicyCheckOperator(func(c int) bool { return c >= 0 })
+
+// lenLessThanPolicyCheckOperator checks if the length of the left value is less than the right value.
+var lenLessThanPolicyCheckOperator = lengthPolicyCheckOperator(func(c int) bool { return c < 0 })
+
+// lenLessThanOrEqualsPolicyCheckOperator checks if the length of the left value is at most the right value.
+var lenLessThanOrEqualsPolicyCheckOperator = lengthPolicyCheckOperator(func(c int) bool { return c <= 0 })
+
+// lenBetweenPolicyCheckOperator checks if the length of the left value lies
+// within the Range in the right value.
+var lenBetweenPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	n, ok := lengthOf(leftVal)
+	if !ok {
+		return false
+	}
+
+	return betweenPolicyCheckOperator(n, rightVal)
+}
@@ -0,0 +1,127 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLengthPolicyCheckOperators(t *testing.T) {
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"len_eq", []string{"a", "b"}, 2, true},
+		{"len_eq", []string{"a", "b"}, 2.0, true},
+		{"len_eq", []string{"a", "b"}, "2", true},
+		{"len_eq", [3]int{}, 3, true},
+		{"len_eq", map[string]int{"a": 1}, 1, true},
+		{"len_eq", "héllo", 5, true}, // Runes, not bytes
+		{"len_eq", "日本語", 3, true},
+		{"len_eq", []string(nil), 0, true},
+		{"len_gt", []int{1, 2, 3}, 2, true},
+		{"len_gt", []int{1, 2}, 2, false},
+		{"len_gte", "abc", 3, true},
+		{"len_lt", "abc", 3, false},
+		{"len_lte", []int{}, 0, true},
+		{"len_between", "twenty characters!!!", []int{20, 500}, true},
+		{"len_between", "short", []int{20, 500}, false},
+		{"len_between", []int{1, 2, 3}, Range{Min: 0, Max: 3, ExcludeMax: true}, false},
+		{"len_eq", 12, 2, false}, // Numbers have no length
+		{"len_eq", nil, 0, false},
+		{"len_eq", "ab", 2.5, false},
+		{"len_gt", "ab", -1, false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Length(t *testing.T) {
+	type Document struct {
+		Description string
+		Tags        []string
+		Labels      map[string]string
+	}
+
+	policies := []Policy{
+		{
+			Name: "WellFormed",
+			Rules: []Rule{
+				{Field: "Tags", Operator: "len_lte", Value: 10},
+				{Field: "Description", Operator: "len_gte", Value: 20},
+				{Field: "Labels", Operator: "len_between", Value: []any{1, 5}},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Document{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	labels := map[string]string{"team": "core"}
+
+	tests := []struct {
+		name     string
+		document Document
+		expected bool
+	}{
+		{"valid", Document{Description: "A long enough description", Tags: []string{"a"}, Labels: labels}, true},
+		{"too many tags", Document{Description: "A long enough description", Tags: make([]string, 11), Labels: labels}, false},
+		{"short description", Document{Description: "Too short", Labels: labels}, false},
+		{"no labels", Document{Description: "A long enough description"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := policies[0].Evaluate(tt.document); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(tt.document); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{"negative", "len_eq", -1},
+		{"fraction", "len_gt", 2.5},
+		{"not a number", "len_lt", "ten"},
+		{"list", "len_eq", []int{1, 2}},
+		{"negative bound", "len_between", []int{-1, 5}},
+		{"string bounds", "len_between", []string{"a", "z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Length", Rules: []Rule{{Field: "Tags", Operator: tt.operator, Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ Tags []string }{})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ Tags []string }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package go_policy_enforcer
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// prefixSet is the prepared value of an "in_cidr" or "not_in_cidr" rule. It is
+// a struct so that it is not mistaken for a list of values.
+type prefixSet struct {
+	prefixes []netip.Prefix
+}
+
+// contains reports whether addr is in any of the prefixes.
+func (s prefixSet) contains(addr netip.Addr) bool {
+	for _, prefix := range s.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toAddr returns v, a netip.Addr, a net.IP or an address string, as an address.
+// Strings of any type, such as "type IP string", and values with a text form
+// are parsed, as stringOf describes. IPv4-mapped IPv6 addresses are unmapped,
+// so ::ffff:10.0.0.1 is in 10.0.0.0/8, and zones are dropped.
+func toAddr(v any) (netip.Addr, bool) {
+	var addr netip.Addr
+
+	switch a := v.(type) {
+	case netip.Addr:
+		addr = a
+	case net.IP:
+		var ok bool
+		if addr, ok = netip.AddrFromSlice(a); !ok {
+			return netip.Addr{}, false
+		}
+	default:
+		s, ok := stringOf(a)
+		if !ok {
+			return netip.Addr{}, false
+		}
+
+		var err error
+		if addr, err = netip.ParseAddr(s); err != nil {
+			return netip.Addr{}, false
+		}
+	}
+
+	return addr.Unmap().WithZone(""), addr.IsValid()
+}
+
+// toPrefix returns v, a netip.Prefix, a *net.IPNet or a CIDR string, as a
+// masked prefix. A single address is the prefix containing only that address.
+func toPrefix(v any) (netip.Prefix, bool) {
+	switch p := utils.DereferencePointer(v).(type) {
+	case netip.Prefix:
+		return p.Masked(), p.IsValid()
+	case net.IPNet:
+		return toPrefix(p.String())
+	case string:
+		if prefix, err := netip.ParsePrefix(p); err == nil {
+			if addr := prefix.Addr(); addr.Is4In6() {
+				prefix = netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96)
+			}
+
+			return prefix.Masked(), prefix.IsValid()
+		}
+	}
+
+	if addr, ok := toAddr(utils.DereferencePointer(v)); ok {
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+
+	return netip.Prefix{}, false
+}
+
+// preparePrefixSet validates the value of an "in_cidr" or "not_in_cidr" rule,
+// a CIDR or a list of CIDRs, and parses it into a prefixSet.
+func preparePrefixSet(value any) (any, error) {
+	if prefix, ok := toPrefix(value); ok {
+		return prefixSet{prefixes: []netip.Prefix{prefix}}, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, newEvaluationError(ErrInvalidRule, "invalid CIDR %v", value)
+	}
+
+	set := prefixSet{prefixes: make([]netip.Prefix, 0, v.Len())}
+
+	for i := 0; i < v.Len(); i++ {
+		prefix, ok := toPrefix(v.Index(i).Interface())
+		if !ok {
+			return nil, newEvaluationError(ErrInvalidRule, "invalid CIDR %v at index %d", v.Index(i).Interface(), i)
+		}
+
+		set.prefixes = append(set.prefixes, prefix)
+	}
+
+	return set, nil
+}
+
+// inCIDR reports whether leftVal is an address within the CIDRs in rightVal. ok
+// is false when either value cannot be used.
+func inCIDR(leftVal, rightVal any) (in, ok bool) {
+	addr, ok := toAddr(leftVal)
+	if !ok {
+		return false, false
+	}
+
+	set, ok := rightVal.(prefixSet)
+	if !ok {
+		prepared, err := preparePrefixSet(rightVal)
+		if err != nil {
+			return false, false
+		}
+
+		set = prepared.(prefixSet)
+	}
+
+	return set.contains(addr), true
+}
+
+// inCIDRPolicyCheckOperator checks if the left value, an IPv4 or IPv6 address,
+// is within the CIDR, or any of the list of CIDRs, in the right value.
+var inCIDRPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	in, ok := inCIDR(leftVal, rightVal)
+	return ok && in
+}
+
+// notInCIDRPolicyCheckOperator checks if the left value, an IPv4 or IPv6
+// address, is outside the CIDR, or all of the list of CIDRs, in the right value.
+// Like in_cidr, it returns false when the left value is not an address.
+var notInCIDRPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	in, ok := inCIDR(leftVal, rightVal)
+	return ok && !in
+}
+
+// addrPredicate returns an operator that checks if the left value is an address
+// for which test returns the right value, true when the rule has no value.
+func addrPredicate(test func(netip.Addr) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		addr, ok := toAddr(leftVal)
+		if !ok {
+			return false
+		}
+
+		expected, ok := predicateExpectation(rightVal)
+
+		return ok && test(addr) == expected
+	}
+}
+
+// isPrivatePolicyCheckOperator checks if the left value is a private address,
+// in 10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16 or fc00::/7.
+var isPrivatePolicyCheckOperator = addrPredicate(netip.Addr.IsPrivate)
+
+// isLoopbackPolicyCheckOperator checks if the left value is a loopback address,
+// in 127.0.0.0/8 or ::1.
+var isLoopbackPolicyCheckOperator = addrPredicate(netip.Addr.IsLoopback)
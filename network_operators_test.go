@@ -0,0 +1,175 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+// ipAddress is a named string type holding an address.
+type ipAddress string
+
+func TestCIDRPolicyCheckOperators(t *testing.T) {
+	_, ipNet, _ := net.ParseCIDR("192.168.0.0/16")
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"in_cidr", "10.1.2.3", "10.0.0.0/8", true},
+		{"in_cidr", "11.1.2.3", "10.0.0.0/8", false},
+		{"in_cidr", "10.1.2.3", "10.1.2.3", true}, // A single address
+		{"in_cidr", "10.1.2.3", "10.1.2.99/8", true},
+		{"in_cidr", "10.1.2.3", []string{"172.16.0.0/12", "10.0.0.0/8"}, true},
+		{"in_cidr", "10.1.2.3", []any{"172.16.0.0/12", "192.168.0.0/16"}, false},
+		{"in_cidr", netip.MustParseAddr("2001:db8::1"), "2001:db8::/32", true},
+		{"in_cidr", "2001:db9::1", "2001:db8::/32", false},
+		{"in_cidr", "::ffff:10.1.2.3", "10.0.0.0/8", true},
+		{"in_cidr", "10.1.2.3", "::ffff:10.0.0.0/104", true},
+		{"in_cidr", "fe80::1%eth0", "fe80::/10", true},
+		{"in_cidr", net.ParseIP("192.168.1.1"), ipNet, true},
+		{"in_cidr", "192.168.1.1", netip.MustParsePrefix("192.168.0.0/16"), true},
+		{"in_cidr", "10.1.2.3", "2001:db8::/32", false},
+		{"in_cidr", "not an ip", "10.0.0.0/8", false},
+		{"in_cidr", 10, "10.0.0.0/8", false},
+		{"in_cidr", "10.1.2.3", "10.0.0.0/33", false},
+		{"not_in_cidr", "11.1.2.3", "10.0.0.0/8", true},
+		{"not_in_cidr", "10.1.2.3", []string{"172.16.0.0/12", "10.0.0.0/8"}, false},
+		{"not_in_cidr", "not an ip", "10.0.0.0/8", false},
+		{"in_cidr", ipAddress("10.1.2.3"), "10.0.0.0/8", true},
+		{"not_in_cidr", ipAddress("10.1.2.3"), "10.0.0.0/8", false},
+		{"in_cidr", ipAddress("not an ip"), "10.0.0.0/8", false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestAddressPredicatePolicyCheckOperators(t *testing.T) {
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"is_private", "10.1.2.3", true, true},
+		{"is_private", "172.31.0.1", nil, true},
+		{"is_private", "fd00::1", true, true},
+		{"is_private", "8.8.8.8", true, false},
+		{"is_private", "8.8.8.8", false, true},
+		{"is_private", "::ffff:192.168.1.1", true, true},
+		{"is_loopback", "127.0.0.1", true, true},
+		{"is_loopback", netip.IPv6Loopback(), true, true},
+		{"is_loopback", "10.1.2.3", true, false},
+		{"is_loopback", "10.1.2.3", false, true},
+		{"is_loopback", "localhost", false, false},
+		{"is_loopback", "127.0.0.1", "yes", false},
+		{"is_private", ipAddress("10.1.2.3"), true, true},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Network(t *testing.T) {
+	type Request struct {
+		ClientIP string
+		Peer     netip.Addr
+		Proxy    ipAddress
+	}
+
+	policies := []Policy{
+		{
+			Name: "InternalOnly",
+			Rules: []Rule{
+				{Field: "ClientIP", Operator: "in_cidr", Value: []any{"10.0.0.0/8", "2001:db8::/32"}},
+				{Field: "ClientIP", Operator: "not_in_cidr", Value: "10.99.0.0/16"},
+				{Field: "Peer", Operator: "is_loopback"},
+				{Field: "Proxy", Operator: "in_cidr", Value: "10.0.0.0/8"},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Request{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	loopback := netip.MustParseAddr("127.0.0.1")
+
+	tests := []struct {
+		name     string
+		request  Request
+		expected bool
+	}{
+		{"internal ipv4", Request{ClientIP: "10.1.2.3", Peer: loopback, Proxy: "10.0.0.2"}, true},
+		{"internal ipv6", Request{ClientIP: "2001:db8::42", Peer: loopback, Proxy: "10.0.0.2"}, true},
+		{"blocked subnet", Request{ClientIP: "10.99.1.1", Peer: loopback, Proxy: "10.0.0.2"}, false},
+		{"external", Request{ClientIP: "8.8.8.8", Peer: loopback, Proxy: "10.0.0.2"}, false},
+		{"remote peer", Request{ClientIP: "10.1.2.3", Peer: netip.MustParseAddr("10.0.0.1"), Proxy: "10.0.0.2"}, false},
+		{"missing address", Request{ClientIP: "", Peer: loopback, Proxy: "10.0.0.2"}, false},
+		{"external proxy", Request{ClientIP: "10.1.2.3", Peer: loopback, Proxy: "8.8.8.8"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := policies[0].Evaluate(tt.request); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(tt.request); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidNetworkRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{"invalid cidr", "in_cidr", "10.0.0.0/33"},
+		{"not an address", "not_in_cidr", "intranet"},
+		{"invalid cidr in list", "in_cidr", []any{"10.0.0.0/8", "bogus"}},
+		{"number", "in_cidr", 10},
+		{"non boolean predicate", "is_private", "yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Network", Rules: []Rule{{Field: "ClientIP", Operator: tt.operator, Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ ClientIP string }{ClientIP: "10.1.2.3"})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ ClientIP string }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// OperatorRegistry is a set of named operators that rules can refer to. It is
+// safe for concurrent use, so operators can be registered while policies are
+// being evaluated.
+//
+// Policies are evaluated against DefaultOperatorRegistry unless a PolicyEnforcer
+// or Program is given its own registry with WithOperatorRegistry, which lets two
+// enforcers, for example for two tenants, use different operators.
+type OperatorRegistry struct {
+	mu        sync.RWMutex
+	operators map[string]registeredOperator
+
+	// generation changes whenever an operator is registered or unregistered, so
+	// policies compiled against the registry can tell they are out of date
+	generation uint64
+}
+
+// registeredOperator is an operator function together with the options that
+// control how rule values reach it.
+type registeredOperator struct {
+	fn ContextPolicyCheckOperator
+	operatorOptions
+}
+
+// operatorOptions are the options of a built-in operator. Operators added with
+// Register or RegisterContext use the zero value.
+type operatorOptions struct {
+	// prepare validates a rule's value once, when the rule is compiled, and
+	// returns the value passed to the operator. Nil when the value is used as is.
+	prepare func(value any) (any, error)
+
+	// exact passes values to the operator without coercing them, so strings
+	// that look like numbers stay strings.
+	exact bool
+
+	// handlesSlices passes slice values to the operator as they are, instead of
+	// comparing them with evaluateSliceComparison.
+	handlesSlices bool
+
+	// missingFields calls the operator with a missingField value when the
+	// rule's field cannot be resolved, instead of failing the rule.
+	missingFields bool
+
+	// strictFn replaces the operator with strict types, for operators such as
+	// "==" that coerce values themselves.
+	strictFn ContextPolicyCheckOperator
+
+	// builtin is true for the operators a registry starts with. Only they are
+	// given numbers from JSON policies, and numeric strings, as exact numbers;
+	// operators added with Register or RegisterContext are given them as a
+	// float64 or int, as encoding/json decodes them. See userOperand.
+	builtin bool
+
+	// strictKinds returns the kinds a field must be compatible with, with strict
+	// types, for exact operators such as "between" whose value holds the values
+	// the field is compared with. Nil when the operator's value is compared with
+	// the field as it is, or, for exact operators, not at all.
+	strictKinds func(value any) []string
+}
+
+// valueKinds returns the kinds a field is checked against with strict types
+// when it is compared with value. ok is false for exact operators without
+// strictKinds, which compare values as they are and are not checked.
+func (o operatorOptions) valueKinds(value any) (kinds []string, ok bool) {
+	switch {
+	case o.strictKinds != nil:
+		return o.strictKinds(value), true
+	case o.exact:
+		return nil, false
+	default:
+		return operandKinds(value), true
+	}
+}
+
+// DefaultOperatorRegistry is the registry used when no other registry is
+// configured. It starts with the built-in operators.
+var DefaultOperatorRegistry = NewOperatorRegistry()
+
+// NewOperatorRegistry creates a registry containing the built-in operators.
+//
+// Returns:
+// - *OperatorRegistry: A new registry, independent of every other registry.
+func NewOperatorRegistry() *OperatorRegistry {
+	r := &OperatorRegistry{
+		operators: make(map[string]registeredOperator, len(policyCheckOperatorMap)+len(policyCheckContextOperatorMap)),
+	}
+
+	for name, fn := range policyCheckOperatorMap {
+		r.operators[name] = builtinOperator(name, withoutContext(fn))
+	}
+
+	for name, fn := range policyCheckContextOperatorMap {
+		r.operators[name] = builtinOperator(name, fn)
+	}
+
+	return r
+}
+
+// builtinOperator returns the built-in operator fn, registered under name, with
+// its options.
+func builtinOperator(name string, fn ContextPolicyCheckOperator) registeredOperator {
+	op := registeredOperator{fn: fn, operatorOptions: policyCheckOperatorOptions[name]}
+	op.builtin = true
+
+	return op
+}
+
+// Register adds an operator to the registry under name.
+//
+// Parameters:
+// - name: The operator string used in rules, e.g. "starts with".
+// - fn: The function that performs the comparison.
+//
+// Returns:
+// - error: An error wrapping ErrInvalidOperator if name is empty, fn is nil or
+// an operator is already registered under name.
+func (r *OperatorRegistry) Register(name string, fn PolicyCheckOperator[any]) error {
+	if fn == nil {
+		return newEvaluationError(ErrInvalidOperator, "operator %q has no function", name)
+	}
+
+	return r.RegisterContext(name, withoutContext(fn))
+}
+
+// RegisterContext adds an operator that needs the evaluation context to the
+// registry under name. See ContextPolicyCheckOperator.
+//
+// Parameters:
+// - name: The operator string used in rules.
+// - fn: The function that performs the comparison.
+//
+// Returns:
+// - error: An error wrapping ErrInvalidOperator if name is empty, fn is nil or
+// an operator is already registered under name.
+func (r *OperatorRegistry) RegisterContext(name string, fn ContextPolicyCheckOperator) error {
+	switch {
+	case name == "":
+		return newEvaluationError(ErrInvalidOperator, "operator name is empty")
+	case fn == nil:
+		return newEvaluationError(ErrInvalidOperator, "operator %q has no function", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.operators[name]; ok {
+		return newEvaluationError(ErrInvalidOperator, "operator %q is already registered", name)
+	}
+
+	r.operators[name] = registeredOperator{fn: fn}
+	r.generation++
+
+	return nil
+}
+
+// Unregister removes the operator registered under name. Built-in operators can
+// be removed too. To replace an operator, Unregister it and then Register the
+// replacement.
+//
+// Parameters:
+// - name: The operator string to remove.
+//
+// Returns:
+// - bool: true if an operator was removed, false if none was registered under name.
+func (r *OperatorRegistry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.operators[name]
+	if ok {
+		delete(r.operators, name)
+		r.generation++
+	}
+
+	return ok
+}
+
+// Lookup returns the operator registered under name. Operators added with
+// Register are returned wrapped so that they ignore the context.
+//
+// Parameters:
+// - name: The operator string to look up.
+//
+// Returns:
+// - ContextPolicyCheckOperator: The operator, or nil if none is registered.
+// - bool: true if an operator is registered under name.
+func (r *OperatorRegistry) Lookup(name string) (ContextPolicyCheckOperator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, ok := r.operators[name]
+
+	return op.fn, ok
+}
+
+// List returns the names of the registered operators in sorted order.
+//
+// Returns:
+// - []string: The operator names.
+func (r *OperatorRegistry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.operators))
+	for name := range r.operators {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// lookup returns the operator registered under name, with its options, or the
+// error evaluation reports for unknown operators. A nil registry is
+// DefaultOperatorRegistry.
+func (r *OperatorRegistry) lookup(name string) (registeredOperator, error) {
+	if r == nil {
+		r = DefaultOperatorRegistry
+	}
+
+	r.mu.RLock()
+	op, ok := r.operators[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return registeredOperator{}, newEvaluationError(ErrOperatorNotSupported, "operator '%s' is not supported", name)
+	}
+
+	return op, nil
+}
+
+// currentGeneration returns the registry's generation, which changes whenever an
+// operator is registered or unregistered. A nil registry is
+// DefaultOperatorRegistry.
+func (r *OperatorRegistry) currentGeneration() uint64 {
+	if r == nil {
+		r = DefaultOperatorRegistry
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.generation
+}
+
+// withoutContext adapts a PolicyCheckOperator to a ContextPolicyCheckOperator
+// that ignores the context.
+func withoutContext(fn PolicyCheckOperator[any]) ContextPolicyCheckOperator {
+	return func(_ context.Context, leftVal, rightVal any) bool {
+		return fn(leftVal, rightVal)
+	}
+}
@@ -0,0 +1,321 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var startsWithOperator = func(leftVal, rightVal any) bool {
+	left, ok1 := leftVal.(string)
+	right, ok2 := rightVal.(string)
+	return ok1 && ok2 && strings.HasPrefix(left, right)
+}
+
+func TestOperatorRegistry_RegisterLookupUnregister(t *testing.T) {
+	registry := NewOperatorRegistry()
+
+	if _, ok := registry.Lookup("prefix"); ok {
+		t.Fatalf("expected prefix not to be registered")
+	}
+
+	if err := registry.Register("prefix", startsWithOperator); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fn, ok := registry.Lookup("prefix")
+	if !ok {
+		t.Fatalf("expected prefix to be registered")
+	}
+
+	if !fn(context.Background(), "policy", "pol") {
+		t.Errorf("expected the registered operator to be returned")
+	}
+
+	if !registry.Unregister("prefix") {
+		t.Errorf("expected Unregister to return true")
+	}
+
+	if registry.Unregister("prefix") {
+		t.Errorf("expected a second Unregister to return false")
+	}
+
+	if _, ok := registry.Lookup("prefix"); ok {
+		t.Errorf("expected prefix to be unregistered")
+	}
+}
+
+func TestOperatorRegistry_RegisterErrors(t *testing.T) {
+	registry := NewOperatorRegistry()
+
+	tests := []struct {
+		name     string
+		register func() error
+	}{
+		{"empty name", func() error { return registry.Register("", startsWithOperator) }},
+		{"nil function", func() error { return registry.Register("prefix", nil) }},
+		{"nil context function", func() error { return registry.RegisterContext("prefix", nil) }},
+		{"built-in name", func() error { return registry.Register("==", startsWithOperator) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.register(); !errors.Is(err, ErrInvalidOperator) {
+				t.Errorf("expected ErrInvalidOperator, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestOperatorRegistry_List(t *testing.T) {
+	registry := NewOperatorRegistry()
+
+	builtins := len(policyCheckOperatorMap) + len(policyCheckContextOperatorMap)
+
+	names := registry.List()
+	if len(names) != builtins {
+		t.Errorf("expected the %d built-in operators, but got %v", builtins, names)
+	}
+
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("expected the names to be sorted, but got %v", names)
+	}
+
+	_ = registry.Register("prefix", startsWithOperator)
+	registry.Unregister("===")
+
+	names = registry.List()
+	if !slices.Contains(names, "prefix") || slices.Contains(names, "===") {
+		t.Errorf("expected prefix to be added and === to be removed, but got %v", names)
+	}
+}
+
+func TestOperatorRegistry_RegistriesAreIndependent(t *testing.T) {
+	tenantA := NewOperatorRegistry()
+	tenantB := NewOperatorRegistry()
+
+	_ = tenantA.Register("prefix", startsWithOperator)
+	tenantB.Unregister(">")
+
+	policies := &[]Policy{
+		{Name: "Prefixed", Rules: []Rule{{Field: "Name", Operator: "prefix", Value: "pol"}}},
+		{Name: "Positive", Rules: []Rule{{Field: "Size", Operator: ">", Value: 0}}},
+	}
+
+	resource := struct {
+		Name string
+		Size int
+	}{Name: "policy", Size: 1}
+
+	tests := []struct {
+		name     string
+		registry *OperatorRegistry
+		expected bool
+	}{
+		{"tenant a", tenantA, true},
+		{"tenant b", tenantB, false},
+		{"default", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforcer := NewPolicyEnforcer(policies, WithOperatorRegistry(tt.registry))
+
+			ok, err := enforcer.EnforceE(resource)
+			if ok != tt.expected {
+				t.Errorf("expected EnforceE to return %v, but got %v", tt.expected, ok)
+			}
+
+			if !tt.expected && !errors.Is(err, ErrOperatorNotSupported) {
+				t.Errorf("expected ErrOperatorNotSupported, but got %v", err)
+			}
+		})
+	}
+
+	if _, ok := DefaultOperatorRegistry.Lookup("prefix"); ok {
+		t.Errorf("expected the default registry not to be changed")
+	}
+}
+
+func TestOperatorRegistry_Compile(t *testing.T) {
+	registry := NewOperatorRegistry()
+	_ = registry.Register("prefix", startsWithOperator)
+
+	policies := []Policy{
+		{Name: "Prefixed", Rules: []Rule{{Field: "Name", Operator: "prefix", Value: "pol"}}},
+	}
+
+	type Resource struct{ Name string }
+
+	if _, err := Compile(policies, Resource{}); !errors.Is(err, ErrOperatorNotSupported) {
+		t.Errorf("expected ErrOperatorNotSupported without the registry, but got %v", err)
+	}
+
+	program, err := Compile(policies, Resource{}, WithOperatorRegistry(registry))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Operators are resolved when compiling
+	registry.Unregister("prefix")
+
+	if !program.Enforce(Resource{Name: "policy"}) {
+		t.Errorf("expected Enforce to return true, but got false")
+	}
+}
+
+func TestOperatorRegistry_ConcurrentUse(t *testing.T) {
+	registry := NewOperatorRegistry()
+
+	policies := &[]Policy{
+		{Name: "Equal", Rules: []Rule{{Field: "Name", Operator: "==", Value: "policy"}}},
+	}
+
+	enforcer := NewPolicyEnforcer(policies, WithOperatorRegistry(registry))
+	resource := struct{ Name string }{Name: "policy"}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("op%d", i)
+			_ = registry.Register(name, startsWithOperator)
+			registry.Unregister(name)
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			if !enforcer.Enforce(resource) {
+				t.Errorf("expected Enforce to return true, but got false")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestOperatorRegistry_Lookup_NonExistingOperator(t *testing.T) {
+	nonExistingOperator := "non_existing_operator"
+
+	opFunc, ok := DefaultOperatorRegistry.Lookup(nonExistingOperator)
+	if ok {
+		t.Errorf("expected operator '%s' not to be found", nonExistingOperator)
+	}
+
+	if opFunc != nil {
+		t.Errorf("expected a nil function for operator '%s'", nonExistingOperator)
+	}
+}
+
+func TestOperatorRegistry_Lookup_CaseSensitivity(t *testing.T) {
+	tests := []struct {
+		operator     string
+		expectedFunc func(any, any) bool
+		expectFound  bool
+	}{
+		{"==", equalsPolicyCheckOperator, true},
+		{"!=", notEqualsPolicyCheckOperator, true},
+		{">=", greaterThanOrEqualsPolicyCheckOperator, true},
+		{"in", inPolicyCheckOperator, true},
+		{"not in", notInPolicyCheckOperator, true},
+		{"within", nil, true}, // Context operators are registered too
+		{"IN", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			opFunc, ok := DefaultOperatorRegistry.Lookup(tt.operator)
+
+			if ok != tt.expectFound || (opFunc != nil) != tt.expectFound {
+				t.Fatalf("Lookup(%q) = %v, %v; want a function: %v", tt.operator, opFunc != nil, ok, tt.expectFound)
+			}
+
+			// Compare function behavior if a function was expected
+			if tt.expectedFunc != nil && opFunc(context.Background(), 10, 10) != tt.expectedFunc(10, 10) {
+				t.Errorf("expected function behavior for operator '%s' does not match", tt.operator)
+			}
+		})
+	}
+}
+
+func TestOperatorRegistry_Lookup_NonStringValues(t *testing.T) {
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"==", 1, 1, true},
+		{"==", 1.0, 1.0, true},
+		{"==", "1", 1, true},
+		{"==", "1.0", 1.0, true},
+		{"!=", 1, 2, true},
+		{"!=", 1.0, 2.0, true},
+		{"!=", "1", 2, true},
+		{"!=", "1.0", 2.0, true},
+	}
+
+	for _, test := range tests {
+		opFunc, ok := DefaultOperatorRegistry.Lookup(test.operator)
+		if !ok {
+			t.Errorf("expected operator '%s' to be registered", test.operator)
+			continue
+		}
+
+		if result := opFunc(context.Background(), test.leftVal, test.rightVal); result != test.expected {
+			t.Errorf("Lookup(%s)(%v, %v) = %v; want %v", test.operator, test.leftVal, test.rightVal, result, test.expected)
+		}
+	}
+}
+
+func TestOperatorRegistry_UserOperatorsReceiveFloats(t *testing.T) {
+	// A user operator written when JSON numbers always decoded to float64
+	atLeast := func(leftVal, rightVal any) bool {
+		left, ok1 := leftVal.(float64)
+		right, ok2 := rightVal.(float64)
+		return ok1 && ok2 && left >= right
+	}
+
+	registry := NewOperatorRegistry()
+	if err := registry.Register("at_least", atLeast); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var policy Policy
+	if err := decodePolicy([]byte(`{"name": "Price", "rules": [{"field": "Price", "operator": "at_least", "value": 19.99}]}`), &policy); err != nil {
+		t.Fatalf("unexpected error decoding the policy: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		price       any
+		strictTypes bool
+		expected    bool
+	}{
+		{"float field", 20.5, false, true},
+		{"decimal string field", "20.5", false, true},
+		{"below the value", 19.5, false, false},
+		{"strict types", 20.5, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := struct{ Price any }{Price: tt.price}
+
+			enforcer := PolicyEnforcer{Policies: &[]Policy{policy}, Operators: registry, StrictTypes: tt.strictTypes}
+
+			result, err := enforcer.EnforceE(resource)
+			if result != tt.expected || err != nil {
+				t.Errorf("EnforceE() = %v, %v; want %v, nil", result, err, tt.expected)
+			}
+		})
+	}
+}
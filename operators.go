@@ -1,8 +1,10 @@
 package go_policy_enforcer
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/kmesiab/go-policy-enforcer/internal/utils"
 )
@@ -10,9 +12,7 @@ import (
 // equalsPolicyCheckOperator checks if two values are equal.
 // Returns true if leftVal is equal to rightVal.
 var equalsPolicyCheckOperator = func(leftVal, rightVal any) bool {
-	leftVal = utils.CoerceToComparable(leftVal)
-	rightVal = utils.CoerceToComparable(rightVal)
-	return reflect.DeepEqual(leftVal, rightVal)
+	return strictEqualsPolicyCheckOperator(utils.CoerceToComparable(leftVal), utils.CoerceToComparable(rightVal))
 }
 
 // notEqualsPolicyCheckOperator checks if two values are not equal.
@@ -21,134 +21,101 @@ var notEqualsPolicyCheckOperator = func(leftVal, rightVal any) bool {
 	return !equalsPolicyCheckOperator(leftVal, rightVal)
 }
 
-// greaterThanPolicyCheckOperator checks if the left value is greater than the right value.
-// Assumes both values are integers. Returns true if leftVal is greater than rightVal.
-var greaterThanPolicyCheckOperator = func(leftVal, rightVal any) bool {
-	switch left := leftVal.(type) {
-	case int:
-		// Handle int vs float64
-		switch right := rightVal.(type) {
-		case int:
-			return left > right
-		case float64:
-			return float64(left) > right
-		default:
-			return false
-		}
-	case float64:
-		// Handle float64 vs int
-		switch right := rightVal.(type) {
-		case int:
-			return left > float64(right)
-		case float64:
-			return left > right
-		default:
-			return false
-		}
-	case string:
-		if right, ok := rightVal.(string); ok {
-			return left > right
-		}
-		return false
-	default:
-		return false
+// strictEqualsPolicyCheckOperator checks if two values are equal without
+// coercing them, and is used for "==" with strict types. Comparable values
+// decide for themselves, numbers of any type are equal by value, strings of
+// any type, and values with a text form, by content, and times by instant.
+var strictEqualsPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	if c, ok, handled := compareCustom(leftVal, rightVal); handled {
+		return ok && c == 0
 	}
-}
 
-// greaterThanOrEqualsPolicyCheckOperator checks if the left value is greater than or equal to the right value.
-// Assumes both values are integers. Returns true if leftVal is greater than or equal to rightVal.
-var greaterThanOrEqualsPolicyCheckOperator = func(leftVal, rightVal any) bool {
-	switch left := leftVal.(type) {
-	case int:
-		switch right := rightVal.(type) {
-		case int:
-			return left >= right
-		case float64:
-			return float64(left) >= right
-		default:
-			return false
-		}
-	case float64:
-		switch right := rightVal.(type) {
-		case int:
-			return left >= float64(right)
-		case float64:
-			return left >= right
-		default:
-			return false
+	if c, ok, handled := compareTemporal(leftVal, rightVal); handled {
+		return ok && c == 0
+	}
+
+	// Numbers are equal by value, whatever their types, so 5 equals 5.0
+	if left, ok := numberOf(leftVal); ok {
+		if right, ok := numberOf(rightVal); ok {
+			c, ok := compareNumbers(left, right)
+			return ok && c == 0
 		}
-	case string:
-		if right, ok := rightVal.(string); ok {
-			return left >= right
+	}
+
+	// Named string types, such as "type Status string", and values with a text
+	// form equal plain strings
+	if left, ok := stringOf(leftVal); ok {
+		if right, ok := stringOf(rightVal); ok {
+			return left == right
 		}
-		return false
-	default:
-		return false
 	}
+
+	return reflect.DeepEqual(leftVal, rightVal)
 }
 
-// lessThanPolicyCheckOperator checks if the left value is less than the right value.
-// Assumes both values are integers. Returns true if leftVal is less than rightVal.
-var lessThanPolicyCheckOperator = func(leftVal, rightVal any) bool {
-	switch left := leftVal.(type) {
-	case int:
-		switch right := rightVal.(type) {
-		case int:
-			return left < right
-		case float64:
-			return float64(left) < right
-		default:
-			return false
-		}
-	case float64:
-		switch right := rightVal.(type) {
-		case int:
-			return left < float64(right)
-		case float64:
-			return left < right
-		default:
-			return false
-		}
-	case string:
-		if right, ok := rightVal.(string); ok {
-			return left < right
-		}
-		return false
-	default:
-		return false
+// strictNotEqualsPolicyCheckOperator checks if two values are not equal without
+// coercing them, and is used for "!=" with strict types.
+var strictNotEqualsPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	return !strictEqualsPolicyCheckOperator(leftVal, rightVal)
+}
+
+// orderingPolicyCheckOperator returns an operator that orders the left and
+// right values with compare and passes when test accepts the result. It fails
+// for values that cannot be ordered, such as a number and a string, or NaN, so
+// NaN is neither greater than nor less than anything, itself included.
+func orderingPolicyCheckOperator(compare func(leftVal, rightVal any) (int, bool), test func(c int) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		c, ok := compare(leftVal, rightVal)
+		return ok && test(c)
 	}
 }
 
+// greaterThanPolicyCheckOperator checks if the left value is greater than the right value.
+// Numbers of any type, times, durations and strings are ordered as "between"
+// orders them, as described by compareOrdered. Returns true if leftVal is greater than rightVal.
+var greaterThanPolicyCheckOperator = orderingPolicyCheckOperator(compareOrdered, func(c int) bool { return c > 0 })
+
+// greaterThanOrEqualsPolicyCheckOperator checks if the left value is greater than or equal to the right value.
+// Returns true if leftVal is greater than or equal to rightVal.
+var greaterThanOrEqualsPolicyCheckOperator = orderingPolicyCheckOperator(compareOrdered, func(c int) bool { return c >= 0 })
+
+// lessThanPolicyCheckOperator checks if the left value is less than the right value.
+// Returns true if leftVal is less than rightVal.
+var lessThanPolicyCheckOperator = orderingPolicyCheckOperator(compareOrdered, func(c int) bool { return c < 0 })
+
 // lessThanOrEqualsPolicyCheckOperator checks if the left value is less than or
 // equal to the right value.
-var lessThanOrEqualsPolicyCheckOperator = func(leftVal, rightVal any) bool {
-	switch left := leftVal.(type) {
-	case int:
-		switch right := rightVal.(type) {
-		case int:
-			return left <= right
-		case float64:
-			return float64(left) <= right
-		default:
-			return false
-		}
-	case float64:
-		switch right := rightVal.(type) {
-		case int:
-			return left <= float64(right)
-		case float64:
-			return left <= right
-		default:
-			return false
-		}
-	case string:
-		if right, ok := rightVal.(string); ok {
-			return left <= right
-		}
-		return false
-	default:
-		return false
+var lessThanOrEqualsPolicyCheckOperator = orderingPolicyCheckOperator(compareOrdered, func(c int) bool { return c <= 0 })
+
+// strictGreaterThanPolicyCheckOperator is ">" with strict types, which orders
+// values with compareOrderedStrictly.
+var strictGreaterThanPolicyCheckOperator = orderingPolicyCheckOperator(compareOrderedStrictly, func(c int) bool { return c > 0 })
+
+// strictGreaterThanOrEqualsPolicyCheckOperator is ">=" with strict types.
+var strictGreaterThanOrEqualsPolicyCheckOperator = orderingPolicyCheckOperator(compareOrderedStrictly, func(c int) bool { return c >= 0 })
+
+// strictLessThanPolicyCheckOperator is "<" with strict types.
+var strictLessThanPolicyCheckOperator = orderingPolicyCheckOperator(compareOrderedStrictly, func(c int) bool { return c < 0 })
+
+// strictLessThanOrEqualsPolicyCheckOperator is "<=" with strict types.
+var strictLessThanOrEqualsPolicyCheckOperator = orderingPolicyCheckOperator(compareOrderedStrictly, func(c int) bool { return c <= 0 })
+
+// stringOf returns v as a string when it is a string of any type, including
+// named types such as "type Status string", or its text when it implements
+// encoding.TextMarshaler or fmt.Stringer.
+func stringOf(v any) (string, bool) {
+	// A json.Number is a number from a policy file, so it does not equal the
+	// string "7", just as the number 7 does not
+	if _, ok := v.(json.Number); ok {
+		return "", false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.String {
+		return textOf(v)
 	}
+
+	return rv.String(), true
 }
 
 // inPolicyCheckOperator checks if the left value exists in a slice of right values.
@@ -182,6 +149,29 @@ var inPolicyCheckOperator = func(leftVal, rightVal any) bool {
 	return false
 }
 
+// compareTemporal compares two values with compareOrdered when either of them is
+// a time.Time or a time.Duration, so that times in different time zones and
+// durations are compared by value. handled is false for other values.
+func compareTemporal(leftVal, rightVal any) (c int, ok, handled bool) {
+	switch leftVal.(type) {
+	case time.Time, time.Duration:
+		handled = true
+	}
+
+	switch rightVal.(type) {
+	case time.Time, time.Duration:
+		handled = true
+	}
+
+	if !handled {
+		return 0, false, false
+	}
+
+	c, ok = compareOrdered(leftVal, rightVal)
+
+	return c, ok, true
+}
+
 // notInPolicyCheckOperator checks if the left value does not exist in a slice of right values.
 var notInPolicyCheckOperator = func(leftVal, rightVal any) bool {
 	return !inPolicyCheckOperator(leftVal, rightVal)
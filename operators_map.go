@@ -4,8 +4,9 @@ import (
 	"github.com/kmesiab/go-policy-enforcer/custom_operators"
 )
 
-// PolicyCheckOperatorMap maps string representations of comparison operators
-// to their corresponding PolicyCheckOperator functions.
+// PolicyCheckOperatorMap maps string representations of the built-in comparison
+// operators to their corresponding PolicyCheckOperator functions. Every
+// OperatorRegistry starts with these operators.
 var policyCheckOperatorMap = map[string]PolicyCheckOperator[any]{
 	"==":     PolicyCheckOperator[any](equalsPolicyCheckOperator),
 	"!=":     PolicyCheckOperator[any](notEqualsPolicyCheckOperator),
@@ -17,4 +18,136 @@ var policyCheckOperatorMap = map[string]PolicyCheckOperator[any]{
 	"<=":     PolicyCheckOperator[any](lessThanOrEqualsPolicyCheckOperator),
 	"in":     PolicyCheckOperator[any](inPolicyCheckOperator),
 	"not in": PolicyCheckOperator[any](notInPolicyCheckOperator),
+
+	"matches":     PolicyCheckOperator[any](matchesPolicyCheckOperator),
+	"not matches": PolicyCheckOperator[any](notMatchesPolicyCheckOperator),
+
+	"contains":               PolicyCheckOperator[any](containsPolicyCheckOperator),
+	"not_contains":           PolicyCheckOperator[any](notContainsPolicyCheckOperator),
+	"starts_with":            PolicyCheckOperator[any](startsWithPolicyCheckOperator),
+	"not_starts_with":        PolicyCheckOperator[any](notStartsWithPolicyCheckOperator),
+	"ends_with":              PolicyCheckOperator[any](endsWithPolicyCheckOperator),
+	"not_ends_with":          PolicyCheckOperator[any](notEndsWithPolicyCheckOperator),
+	"equals_ignore_case":     PolicyCheckOperator[any](equalsIgnoreCasePolicyCheckOperator),
+	"not_equals_ignore_case": PolicyCheckOperator[any](notEqualsIgnoreCasePolicyCheckOperator),
+	"all_contains":           PolicyCheckOperator[any](allContainPolicyCheckOperator),
+	"all_starts_with":        PolicyCheckOperator[any](allStartWithPolicyCheckOperator),
+	"all_ends_with":          PolicyCheckOperator[any](allEndWithPolicyCheckOperator),
+	"all_equals_ignore_case": PolicyCheckOperator[any](allEqualIgnoreCasePolicyCheckOperator),
+
+	"glob":     PolicyCheckOperator[any](globPolicyCheckOperator),
+	"glob_any": PolicyCheckOperator[any](globAnyPolicyCheckOperator),
+
+	"between":   PolicyCheckOperator[any](betweenPolicyCheckOperator),
+	"approx_eq": PolicyCheckOperator[any](approxEqualsPolicyCheckOperator),
+
+	"in_cidr":     PolicyCheckOperator[any](inCIDRPolicyCheckOperator),
+	"not_in_cidr": PolicyCheckOperator[any](notInCIDRPolicyCheckOperator),
+	"is_private":  PolicyCheckOperator[any](isPrivatePolicyCheckOperator),
+	"is_loopback": PolicyCheckOperator[any](isLoopbackPolicyCheckOperator),
+
+	"semver_eq":        PolicyCheckOperator[any](semverEqualsPolicyCheckOperator),
+	"semver_gt":        PolicyCheckOperator[any](semverGreaterThanPolicyCheckOperator),
+	"semver_gte":       PolicyCheckOperator[any](semverGreaterThanOrEqualsPolicyCheckOperator),
+	"semver_lt":        PolicyCheckOperator[any](semverLessThanPolicyCheckOperator),
+	"semver_lte":       PolicyCheckOperator[any](semverLessThanOrEqualsPolicyCheckOperator),
+	"semver_satisfies": PolicyCheckOperator[any](semverSatisfiesPolicyCheckOperator),
+
+	"exists":       PolicyCheckOperator[any](existsPolicyCheckOperator),
+	"not_exists":   PolicyCheckOperator[any](notExistsPolicyCheckOperator),
+	"is_nil":       PolicyCheckOperator[any](isNilPolicyCheckOperator),
+	"is_empty":     PolicyCheckOperator[any](isEmptyPolicyCheckOperator),
+	"is_not_empty": PolicyCheckOperator[any](isNotEmptyPolicyCheckOperator),
+
+	"len_eq":      PolicyCheckOperator[any](lenEqualsPolicyCheckOperator),
+	"len_gt":      PolicyCheckOperator[any](lenGreaterThanPolicyCheckOperator),
+	"len_gte":     PolicyCheckOperator[any](lenGreaterThanOrEqualsPolicyCheckOperator),
+	"len_lt":      PolicyCheckOperator[any](lenLessThanPolicyCheckOperator),
+	"len_lte":     PolicyCheckOperator[any](lenLessThanOrEqualsPolicyCheckOperator),
+	"len_between": PolicyCheckOperator[any](lenBetweenPolicyCheckOperator),
+
+	"subset_of":    PolicyCheckOperator[any](subsetOfPolicyCheckOperator),
+	"superset_of":  PolicyCheckOperator[any](supersetOfPolicyCheckOperator),
+	"contains_all": PolicyCheckOperator[any](supersetOfPolicyCheckOperator),
+	"intersects":   PolicyCheckOperator[any](intersectsPolicyCheckOperator),
+	"contains_any": PolicyCheckOperator[any](intersectsPolicyCheckOperator),
+	"disjoint":     PolicyCheckOperator[any](disjointPolicyCheckOperator),
+}
+
+// policyCheckContextOperatorMap maps the built-in operators that need the
+// evaluation context, such as the time operators that read the clock set with
+// WithClock, to their functions. Every OperatorRegistry starts with these too.
+var policyCheckContextOperatorMap = map[string]ContextPolicyCheckOperator{
+	"before": beforePolicyCheckOperator,
+	"after":  afterPolicyCheckOperator,
+	"within": withinPolicyCheckOperator,
+}
+
+// policyCheckOperatorOptions holds the options of the built-in operators that
+// need them. Operators that are not listed use the zero value.
+var policyCheckOperatorOptions = map[string]operatorOptions{
+	"==": {strictFn: withoutContext(strictEqualsPolicyCheckOperator)},
+	"!=": {strictFn: withoutContext(strictNotEqualsPolicyCheckOperator)},
+	">":  {strictFn: withoutContext(strictGreaterThanPolicyCheckOperator)},
+	">=": {strictFn: withoutContext(strictGreaterThanOrEqualsPolicyCheckOperator)},
+	"<":  {strictFn: withoutContext(strictLessThanPolicyCheckOperator)},
+	"<=": {strictFn: withoutContext(strictLessThanOrEqualsPolicyCheckOperator)},
+
+	"matches":     {prepare: preparePattern, exact: true, handlesSlices: true},
+	"not matches": {prepare: preparePattern, exact: true, handlesSlices: true},
+
+	"contains":               stringOperatorOptions,
+	"not_contains":           stringOperatorOptions,
+	"starts_with":            stringOperatorOptions,
+	"not_starts_with":        stringOperatorOptions,
+	"ends_with":              stringOperatorOptions,
+	"not_ends_with":          stringOperatorOptions,
+	"equals_ignore_case":     stringOperatorOptions,
+	"not_equals_ignore_case": stringOperatorOptions,
+	"all_contains":           stringOperatorOptions,
+	"all_starts_with":        stringOperatorOptions,
+	"all_ends_with":          stringOperatorOptions,
+	"all_equals_ignore_case": stringOperatorOptions,
+
+	"glob":     {prepare: prepareGlob, exact: true, handlesSlices: true},
+	"glob_any": {prepare: prepareGlobList, exact: true, handlesSlices: true},
+
+	"between":   {prepare: prepareRange, exact: true, handlesSlices: true, strictFn: withoutContext(strictBetweenPolicyCheckOperator), strictKinds: rangeKinds},
+	"approx_eq": {prepare: prepareTolerance, exact: true, handlesSlices: true},
+
+	"in_cidr":     {prepare: preparePrefixSet, exact: true, handlesSlices: true},
+	"not_in_cidr": {prepare: preparePrefixSet, exact: true, handlesSlices: true},
+	"is_private":  {prepare: preparePredicate, exact: true, handlesSlices: true},
+	"is_loopback": {prepare: preparePredicate, exact: true, handlesSlices: true},
+
+	"semver_eq":        {prepare: prepareSemver, exact: true},
+	"semver_gt":        {prepare: prepareSemver, exact: true},
+	"semver_gte":       {prepare: prepareSemver, exact: true},
+	"semver_lt":        {prepare: prepareSemver, exact: true},
+	"semver_lte":       {prepare: prepareSemver, exact: true},
+	"semver_satisfies": {prepare: prepareSemverConstraint, exact: true},
+
+	"before": {prepare: prepareTimeValue, exact: true},
+	"after":  {prepare: prepareTimeValue, exact: true},
+	"within": {prepare: prepareWithin, exact: true},
+
+	"exists":       existenceOperatorOptions,
+	"not_exists":   existenceOperatorOptions,
+	"is_nil":       existenceOperatorOptions,
+	"is_empty":     existenceOperatorOptions,
+	"is_not_empty": existenceOperatorOptions,
+
+	"len_eq":      {prepare: prepareLength, exact: true, handlesSlices: true},
+	"len_gt":      {prepare: prepareLength, exact: true, handlesSlices: true},
+	"len_gte":     {prepare: prepareLength, exact: true, handlesSlices: true},
+	"len_lt":      {prepare: prepareLength, exact: true, handlesSlices: true},
+	"len_lte":     {prepare: prepareLength, exact: true, handlesSlices: true},
+	"len_between": {prepare: prepareLengthRange, exact: true, handlesSlices: true},
+
+	"subset_of":    setOperatorOptions,
+	"superset_of":  setOperatorOptions,
+	"contains_all": setOperatorOptions,
+	"intersects":   setOperatorOptions,
+	"contains_any": setOperatorOptions,
+	"disjoint":     setOperatorOptions,
 }
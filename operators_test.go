@@ -1,6 +1,10 @@
 package go_policy_enforcer
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -111,90 +115,197 @@ func TestLessThanPolicyCheckOperator(t *testing.T) {
 	}
 }
 
-// TestEvaluatePolicyCheckOperator tests the evaluatePolicyCheckOperator function
-func TestEvaluatePolicyCheckOperator(t *testing.T) {
+// TestComparisonOperators_NumericKinds tests that the comparison operators
+// compare numbers of every kind, including named types, by value
+func TestComparisonOperators_NumericKinds(t *testing.T) {
+	type Cents int64
+	type Ratio float32
+
 	tests := []struct {
 		operator string
 		leftVal  any
 		rightVal any
 		expected bool
 	}{
-		{"==", 1, 1, true},
-		{"==", "abc", "abc", true},
-		{"!=", 1, 2, true},
-		{">", 2, 1, true},
-		{">", "b", "a", true}, // String comparison
-		{">=", 2, 1, true},
-		{"<", 1, 2, true},
-		{"<", "a", "b", true}, // String comparison
-		{"<", 1, 1, false},
+		{">", int64(10), 5, true},
+		{">", uint32(10), 5.5, true},
+		{">", float32(2.5), 2, true},
+		{">", int8(-1), uint64(0), false},
+		{">", Cents(1500), 1000, true},
+		{">=", Cents(1000), 1000.0, true},
+		{"<", Ratio(0.25), 0.5, true},
+		{"<", uint64(math.MaxUint64), int64(math.MaxInt64), false},
+		{"<=", int64(math.MaxInt64), float64(1 << 63), true},
+		{"<", int64(math.MaxInt64), float64(1 << 63), true},
+		{">", int64(1<<53 + 1), float64(1 << 53), true},
+		{"==", int64(1<<53 + 1), float64(1 << 53), false},
+		{"==", int64(5), 5.0, true},
+		{"==", Cents(500), 500, true},
+		{"==", uint16(7), int32(7), true},
+		{"!=", int64(5), 5.5, true},
+		{">", math.NaN(), 1, false},
+		{"<", math.NaN(), 1, false},
+		{"==", math.NaN(), math.NaN(), false},
+		{"!=", math.NaN(), math.NaN(), true},
 	}
 
 	for _, test := range tests {
 		result, err := evaluatePolicyCheckOperator(test.operator, test.leftVal, test.rightVal)
-
 		if err != nil {
-			t.Errorf("Error evaluating policy check operator: %v", err)
-			continue
+			t.Errorf("evaluatePolicyCheckOperator(%s, %v, %v) returned error: %v", test.operator, test.leftVal, test.rightVal, err)
 		}
-
 		if result != test.expected {
-			t.Errorf("EvaluatePolicyCheckOperator(%v, %v, %v) = %v; want %v", test.operator, test.leftVal, test.rightVal, result, test.expected)
+			t.Errorf("evaluatePolicyCheckOperator(%s, %v (%T), %v (%T)) = %v; want %v", test.operator, test.leftVal, test.leftVal, test.rightVal, test.rightVal, result, test.expected)
 		}
 	}
 }
 
-func TestGetPolicyCheckOperator_NonExistingOperator(t *testing.T) {
+// TestComparisonOperators_NumericFields tests comparisons on struct fields of
+// named and sized numeric types in a policy, evaluated and compiled
+func TestComparisonOperators_NumericFields(t *testing.T) {
+	type Cents int64
+
+	type invoice struct {
+		Total    Cents
+		Items    uint32
+		Discount float32
+		ID       int64
+	}
+
+	policy := Policy{
+		Name: "Invoice",
+		Rules: []Rule{
+			{Field: "Total", Operator: ">=", Value: 10000.0},
+			{Field: "Items", Operator: "<", Value: 5},
+			{Field: "Discount", Operator: "<=", Value: 0.25},
+			{Field: "ID", Operator: "==", Value: 9007199254740993.0},
+		},
+	}
 
-	nonExistingOperator := "non_existing_operator"
-	opFunc, err := getPolicyCheckOperator(nonExistingOperator)
+	input := invoice{Total: 12500, Items: 3, Discount: 0.25, ID: 1<<53 + 1}
 
-	if err == nil {
-		t.Errorf("Expected error for non existing operator but got: %v", err)
+	// 1<<53+1 cannot be held by a float64, so the ID rule fails
+	if policy.Evaluate(input) {
+		t.Error("Evaluate() = true; want false")
 	}
 
-	if opFunc != nil {
-		t.Errorf("Expected nil function for operator '%s', but got nil", nonExistingOperator)
+	policy.Rules[3].Value = 9007199254740992.0
+	if policy.Evaluate(input) {
+		t.Error("Evaluate() = true; want false")
+	}
+
+	policy.Rules[3].Value = int64(1<<53 + 1)
+	if !policy.Evaluate(input) {
+		t.Error("Evaluate() = false; want true")
+	}
+
+	program, err := Compile([]Policy{policy}, invoice{})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if !program.Enforce(input) {
+		t.Error("compiled Enforce() = false; want true")
 	}
 }
 
-func TestGetPolicyCheckOperator_CaseSensitivity(t *testing.T) {
+func TestComparisonOperators_DecimalFields(t *testing.T) {
+	type payment struct {
+		Amount  *big.Rat
+		Balance big.Int
+		Rate    *big.Float
+		Quoted  string
+		Raw     json.Number
+	}
+
+	input := payment{
+		Amount: big.NewRat(1999, 100),
+		Rate:   big.NewFloat(0.5),
+		Quoted: "12345678901234567.01",
+		Raw:    json.Number("0.10"),
+	}
+	input.Balance.SetString("123456789012345678901234567890", 10)
+
 	tests := []struct {
-		operator     string
-		expectedFunc func(any, any) bool
-		expectNil    bool
-		expectError  bool
+		name      string
+		rule      Rule
+		expected  bool
+		strictErr error
 	}{
-		{"==", equalsPolicyCheckOperator, false, false},
-		{"!=", notEqualsPolicyCheckOperator, false, false},
-		{">=", greaterThanOrEqualsPolicyCheckOperator, false, false},
-		{"in", inPolicyCheckOperator, false, false},
-		{"not in", notInPolicyCheckOperator, false, false},
-		{"IN", nil, true, true}, // Case-sensitive mismatch should return nil without error
+		{"rat and float", Rule{Field: "Amount", Operator: "==", Value: 19.99}, true, nil},
+		{"rat and decimal string", Rule{Field: "Amount", Operator: "==", Value: "19.990"}, true, ErrTypeMismatch},
+		{"rat and json number", Rule{Field: "Amount", Operator: "<", Value: json.Number("19.991")}, true, nil},
+		{"rat between", Rule{Field: "Amount", Operator: "between", Value: []any{json.Number("0.01"), 19.99}}, true, nil},
+		{"rat in", Rule{Field: "Amount", Operator: "in", Value: []any{json.Number("9.99"), json.Number("19.99")}}, true, nil},
+		{"rat not in", Rule{Field: "Amount", Operator: "not in", Value: []any{19.98, 20}}, true, nil},
+		{"big int", Rule{Field: "Balance", Operator: ">", Value: "123456789012345678901234567889"}, true, ErrTypeMismatch},
+		{"big int equal", Rule{Field: "Balance", Operator: "==", Value: json.Number("123456789012345678901234567890")}, true, nil},
+		{"big float", Rule{Field: "Rate", Operator: "<=", Value: 0.5}, true, nil},
+		{"cents past float precision", Rule{Field: "Quoted", Operator: "<", Value: "12345678901234567.02"}, true, nil},
+		{"cents past float precision differ", Rule{Field: "Quoted", Operator: "==", Value: "12345678901234567.02"}, false, nil},
+		{"json number field", Rule{Field: "Raw", Operator: "==", Value: 0.1}, true, nil},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.operator, func(t *testing.T) {
-			opFunc, err := getPolicyCheckOperator(tt.operator)
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Payment", Rules: []Rule{tt.rule}}
 
-			// Check for unexpected errors
-			if (err != nil) != tt.expectError {
-				t.Errorf("Unexpected error for operator '%s': got %v, want error: %v", tt.operator, err, tt.expectError)
+			result, err := policy.EvaluateE(input)
+			if result != tt.expected || err != nil {
+				t.Errorf("EvaluateE() = %v, %v; want %v, nil", result, err, tt.expected)
 			}
 
-			// Check for expected nil or non-nil function
-			if (opFunc == nil) != tt.expectNil {
-				t.Errorf("Expected nil: %v for operator '%s', but got: %v", tt.expectNil, tt.operator, opFunc)
+			program, err := Compile([]Policy{policy}, payment{})
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
 			}
 
-			// Compare function behavior if a non-nil function was expected
-			if !tt.expectNil && opFunc != nil && !compareFunctions(opFunc, tt.expectedFunc) {
-				t.Errorf("Expected function behavior for operator '%s' does not match", tt.operator)
+			if result := program.Enforce(input); result != tt.expected {
+				t.Errorf("compiled Enforce() = %v; want %v", result, tt.expected)
+			}
+
+			// Numbers written as strings are only parsed without strict types
+			policy.StrictTypes = true
+			if _, err := policy.EvaluateE(input); !errors.Is(err, tt.strictErr) || (err == nil) != (tt.strictErr == nil) {
+				t.Errorf("EvaluateE() with strict types error = %v; want %v", err, tt.strictErr)
 			}
 		})
 	}
 }
 
+// TestEvaluatePolicyCheckOperator tests the evaluatePolicyCheckOperator function
+func TestEvaluatePolicyCheckOperator(t *testing.T) {
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"==", 1, 1, true},
+		{"==", "abc", "abc", true},
+		{"!=", 1, 2, true},
+		{">", 2, 1, true},
+		{">", "b", "a", true}, // String comparison
+		{">=", 2, 1, true},
+		{"<", 1, 2, true},
+		{"<", "a", "b", true}, // String comparison
+		{"<", 1, 1, false},
+	}
+
+	for _, test := range tests {
+		result, err := evaluatePolicyCheckOperator(test.operator, test.leftVal, test.rightVal)
+
+		if err != nil {
+			t.Errorf("Error evaluating policy check operator: %v", err)
+			continue
+		}
+
+		if result != test.expected {
+			t.Errorf("EvaluatePolicyCheckOperator(%v, %v, %v) = %v; want %v", test.operator, test.leftVal, test.rightVal, result, test.expected)
+		}
+	}
+}
+
 func TestEvaluatePolicyCheckOperator_NilValues(t *testing.T) {
 	operator := "=="
 
@@ -272,41 +383,6 @@ func TestNonNumericValues(t *testing.T) {
 	}
 }
 
-func TestGetPolicyCheckOperator_NonStringValues(t *testing.T) {
-	tests := []struct {
-		operator string
-		leftVal  any
-		rightVal any
-		expected bool
-	}{
-		{"==", 1, 1, true},
-		{"==", 1.0, 1.0, true},
-		{"==", "1", 1, true},
-		{"==", "1.0", 1.0, true},
-		{"!=", 1, 2, true},
-		{"!=", 1.0, 2.0, true},
-		{"!=", "1", 2, true},
-		{"!=", "1.0", 2.0, true},
-	}
-
-	for _, test := range tests {
-		opFunc, err := getPolicyCheckOperator(test.operator)
-
-		if err != nil {
-			t.Errorf("Expected no error for non existing operator but got: %v", err)
-		}
-
-		if opFunc == nil {
-			t.Errorf("Expected non-nil function for operator '%s', but got nil", test.operator)
-		} else {
-			result := opFunc(test.leftVal, test.rightVal)
-			if result != test.expected {
-				t.Errorf("GetPolicyCheckOperator(%s, %v, %v) = %v; want %v", test.operator, test.leftVal, test.rightVal, result, test.expected)
-			}
-		}
-	}
-}
-
 func TestEvaluatePolicyCheckOperator_UnsupportedOperator(t *testing.T) {
 	operator := "unsupported_operator"
 	leftVal := 10
@@ -613,11 +689,3 @@ func TestToStringSlice_DifferentDataTypes(t *testing.T) {
 		}
 	}
 }
-
-// Helper function to compare two functions by applying them on the same test data
-func compareFunctions(f1, f2 PolicyCheckOperator[any]) bool {
-	// Test with sample values, adjust these according to the operator being tested
-	testValue1 := 10
-	testValue2 := 10
-	return f1(testValue1, testValue2) == f2(testValue1, testValue2)
-}
@@ -0,0 +1,378 @@
+package go_policy_enforcer
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// compareOrdered compares two values that have a natural order and returns -1,
+// 0 or +1 as leftVal is less than, equal to or greater than rightVal. ok is
+// false when the values cannot be ordered against each other.
+//
+// Values are ordered as follows:
+//   - Comparable values by their ComparePolicyValue method.
+//   - time.Time against a time.Time or an RFC 3339 string.
+//   - time.Duration against a time.Duration or a duration string such as "90m".
+//   - Numbers of any type, including json.Number and the math/big types, and
+//     numeric strings, by value, exactly. NaN cannot be ordered, and +Inf and
+//     -Inf are greater and less than every other number and equal to
+//     themselves.
+//   - Other strings, and values implementing encoding.TextMarshaler or
+//     fmt.Stringer as their text, as durations or RFC 3339 times when both
+//     parse as one, and lexically otherwise.
+//
+// The comparison operators and "between" all order values with compareOrdered,
+// or with compareOrderedStrictly when strict types are enabled.
+func compareOrdered(leftVal, rightVal any) (c int, ok bool) {
+	return orderValues(leftVal, rightVal, true)
+}
+
+// compareOrderedStrictly compares two values as compareOrdered does, except
+// that numeric strings are not parsed, so "007" is less than "1". It orders
+// values when strict types are enabled. Times and durations written as strings
+// are still parsed.
+func compareOrderedStrictly(leftVal, rightVal any) (c int, ok bool) {
+	return orderValues(leftVal, rightVal, false)
+}
+
+// orderValues implements compareOrdered, parsing numeric strings as numbers
+// when numericStrings is true.
+func orderValues(leftVal, rightVal any, numericStrings bool) (c int, ok bool) {
+	leftVal = utils.DereferencePointer(leftVal)
+	rightVal = utils.DereferencePointer(rightVal)
+
+	if c, ok, handled := compareCustom(leftVal, rightVal); handled {
+		return c, ok
+	}
+
+	switch left := leftVal.(type) {
+	case time.Time:
+		right, ok := toTime(rightVal)
+		if !ok {
+			return 0, false
+		}
+
+		return left.Compare(right), true
+	case time.Duration:
+		right, ok := toDuration(rightVal)
+		if !ok {
+			return 0, false
+		}
+
+		return cmp.Compare(left, right), true
+	}
+
+	switch rightVal.(type) {
+	case time.Time, time.Duration:
+		c, ok := orderValues(rightVal, leftVal, numericStrings)
+		return -c, ok
+	}
+
+	number := numberOf
+	if numericStrings {
+		number = toNumber
+	}
+
+	if left, ok := number(leftVal); ok {
+		if right, ok := number(rightVal); ok {
+			return compareNumbers(left, right)
+		}
+	}
+
+	left, ok := stringOf(leftVal)
+	if !ok {
+		return 0, false
+	}
+
+	right, ok := stringOf(rightVal)
+	if !ok {
+		return 0, false
+	}
+
+	if l, err := time.ParseDuration(left); err == nil {
+		if r, err := time.ParseDuration(right); err == nil {
+			return cmp.Compare(l, r), true
+		}
+	}
+
+	if l, ok := toTime(left); ok {
+		if r, ok := toTime(right); ok {
+			return l.Compare(r), true
+		}
+	}
+
+	return strings.Compare(left, right), true
+}
+
+// toTime returns v as a time, parsing strings as RFC 3339.
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// toDuration returns v as a duration, parsing strings with time.ParseDuration.
+func toDuration(v any) (time.Duration, bool) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, true
+	case string:
+		parsed, err := time.ParseDuration(d)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toNumber returns v as an int64, uint64, float64 or *big.Rat, whichever holds
+// it exactly. Numeric strings are parsed, as CoerceToComparable does, except
+// for "NaN" and "Inf", which stay strings.
+func toNumber(v any) (any, bool) {
+	if n, ok := numberOf(v); ok {
+		return n, true
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, false
+	}
+
+	return parseNumber(s)
+}
+
+// numberOf returns v, a value of any integer or floating point kind, including
+// named types such as "type Cents int64", a json.Number, or a big.Int, big.Rat
+// or big.Float, as an int64, uint64, float64 or *big.Rat.
+func numberOf(v any) (any, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return parseNumber(string(n))
+	case *big.Int:
+		return bigIntNumber(n)
+	case big.Int:
+		return bigIntNumber(&n)
+	case *big.Rat:
+		return n, n != nil
+	case big.Rat:
+		return &n, true
+	case *big.Float:
+		return bigFloatNumber(n)
+	case big.Float:
+		return bigFloatNumber(&n)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return nil, false
+	}
+}
+
+// parseNumber parses a numeric string. Whole numbers become an int64 or uint64
+// and other numbers a *big.Rat, so decimals such as "19.99" keep every digit.
+func parseNumber(s string) (any, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, true
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+
+	if r, ok := new(big.Rat).SetString(s); ok && !strings.Contains(s, "/") {
+		return r, true
+	}
+
+	return f, true
+}
+
+// bigIntNumber returns n as an int64 or uint64 when it fits one, and as a
+// *big.Rat otherwise.
+func bigIntNumber(n *big.Int) (any, bool) {
+	switch {
+	case n == nil:
+		return nil, false
+	case n.IsInt64():
+		return n.Int64(), true
+	case n.IsUint64():
+		return n.Uint64(), true
+	default:
+		return new(big.Rat).SetInt(n), true
+	}
+}
+
+// bigFloatNumber returns f as a *big.Rat holding its exact value, or as an
+// infinite float64.
+func bigFloatNumber(f *big.Float) (any, bool) {
+	switch {
+	case f == nil:
+		return nil, false
+	case f.IsInf():
+		return math.Inf(f.Sign()), true
+	default:
+		r, _ := f.Rat(nil)
+		return r, true
+	}
+}
+
+// compareNumbers compares two values returned by numberOf or toNumber exactly,
+// without the overflow or rounding of converting one to the other's type, so
+// math.MaxInt64 is less than math.MaxUint64 and 1<<53+1 is greater than the
+// float64 1<<53. ok is false when either value is NaN.
+func compareNumbers(left, right any) (c int, ok bool) {
+	_, leftRat := left.(*big.Rat)
+	_, rightRat := right.(*big.Rat)
+
+	if leftRat || rightRat {
+		return compareRats(left, right)
+	}
+
+	switch l := left.(type) {
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			return cmp.Compare(l, r), true
+		case uint64:
+			if l < 0 {
+				return -1, true
+			}
+			return cmp.Compare(uint64(l), r), true
+		case float64:
+			return compareIntFloat(l, r)
+		}
+	case uint64:
+		switch r := right.(type) {
+		case uint64:
+			return cmp.Compare(l, r), true
+		case int64:
+			c, ok := compareNumbers(r, l)
+			return -c, ok
+		case float64:
+			return compareUintFloat(l, r)
+		}
+	case float64:
+		switch r := right.(type) {
+		case float64:
+			if math.IsNaN(l) || math.IsNaN(r) {
+				return 0, false
+			}
+			return cmp.Compare(l, r), true
+		case int64, uint64:
+			c, ok := compareNumbers(r, l)
+			return -c, ok
+		}
+	}
+
+	return 0, false
+}
+
+// compareRats compares two numbers, at least one of which is a *big.Rat, as
+// rationals.
+func compareRats(left, right any) (int, bool) {
+	l, leftInf, ok := toRat(left)
+	if !ok {
+		return 0, false
+	}
+
+	r, rightInf, ok := toRat(right)
+	if !ok {
+		return 0, false
+	}
+
+	if leftInf != 0 || rightInf != 0 {
+		return cmp.Compare(leftInf, rightInf), true
+	}
+
+	return l.Cmp(r), true
+}
+
+// toRat returns a number returned by numberOf as a *big.Rat, or the sign of an
+// infinite float64 as inf. A float64 stands for the shortest decimal that rounds
+// to it, so the float64 19.99 equals the decimal 19.99. ok is false for NaN.
+func toRat(n any) (r *big.Rat, inf int, ok bool) {
+	switch n := n.(type) {
+	case *big.Rat:
+		return n, 0, true
+	case int64:
+		return new(big.Rat).SetInt64(n), 0, true
+	case uint64:
+		return new(big.Rat).SetUint64(n), 0, true
+	case float64:
+		switch {
+		case math.IsNaN(n):
+			return nil, 0, false
+		case math.IsInf(n, 0):
+			return nil, int(math.Copysign(1, n)), true
+		}
+
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+		return r, 0, true
+	default:
+		return nil, 0, false
+	}
+}
+
+// compareIntFloat compares an int64 with a float64 exactly.
+func compareIntFloat(i int64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f >= 0x1p63:
+		return -1, true
+	case f < -0x1p63:
+		return 1, true
+	}
+
+	// f is within the range of an int64, so its integer part converts exactly
+	whole := math.Trunc(f)
+	if c := cmp.Compare(i, int64(whole)); c != 0 {
+		return c, true
+	}
+
+	return cmp.Compare(0, f-whole), true
+}
+
+// compareUintFloat compares a uint64 with a float64 exactly.
+func compareUintFloat(u uint64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f < 0:
+		return 1, true
+	case f >= 0x1p64:
+		return -1, true
+	}
+
+	whole := math.Trunc(f)
+	if c := cmp.Compare(u, uint64(whole)); c != 0 {
+		return c, true
+	}
+
+	return cmp.Compare(0, f-whole), true
+}
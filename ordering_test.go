@@ -0,0 +1,149 @@
+package go_policy_enforcer
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestCompareOrdered(t *testing.T) {
+	noon := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	type Celsius float32
+
+	tests := []struct {
+		name     string
+		leftVal  any
+		rightVal any
+		expected int
+		ok       bool
+	}{
+		{"ints", 1, 2, -1, true},
+		{"int and float", 2, 1.5, 1, true},
+		{"mixed widths", int8(5), uint64(5), 0, true},
+		{"negative and unsigned", -1, uint64(math.MaxUint64), -1, true},
+		{"large integers stay exact", int64(math.MaxInt64), int64(math.MaxInt64 - 1), 1, true},
+		{"named type", Celsius(21.5), 20, 1, true},
+		{"int64 and float above its range", int64(math.MaxInt64), float64(1 << 63), -1, true},
+		{"int64 and float below its range", int64(math.MinInt64), -0x1p64, 1, true},
+		{"int64 and float equal", int64(math.MinInt64), -0x1p63, 0, true},
+		{"int64 past float precision", int64(1<<53 + 1), float64(1 << 53), 1, true},
+		{"int64 and fraction", int64(3), 3.5, -1, true},
+		{"negative int64 and fraction", int64(-3), -3.5, 1, true},
+		{"uint64 and float above its range", uint64(math.MaxUint64), float64(1 << 64), -1, true},
+		{"uint64 past float precision", uint64(math.MaxUint64), float64(math.MaxUint64), -1, true},
+		{"uint64 and negative float", uint64(0), -0.5, 1, true},
+		{"uint64 and fraction", uint64(7), 7.25, -1, true},
+		{"float32", float32(0.5), 0.5, 0, true},
+		{"infinity", math.Inf(1), int64(math.MaxInt64), 1, true},
+		{"NaN", math.NaN(), 1, 0, false},
+		{"NaN and int64", int64(1), math.NaN(), 0, false},
+		{"NaN and uint64", uint64(1), math.NaN(), 0, false},
+		{"NaN and Inf strings are words", "NaN", "Inf", 1, true},
+		{"numeric string", "10", 9, 1, true},
+		{"decimal strings", "0.30000000000000001", "0.3", 1, true},
+		{"decimal string past float precision", "12345678901234567.01", "12345678901234567.02", -1, true},
+		{"json number", json.Number("19.99"), 19.99, 0, true},
+		{"invalid json number", json.Number("abc"), 1, 0, false},
+		{"rat and decimal string", big.NewRat(1999, 100), "19.99", 0, true},
+		{"rat and float", big.NewRat(1, 10), 0.1, 0, true},
+		{"rat value", *big.NewRat(1, 3), "0.3333333333333333", 1, true},
+		{"big int past uint64", new(big.Int).Lsh(big.NewInt(1), 70), uint64(math.MaxUint64), 1, true},
+		{"big int and json number", big.NewInt(-42), json.Number("-42"), 0, true},
+		{"big float", big.NewFloat(2.5), "2.5", 0, true},
+		{"big float infinity", new(big.Float).SetInf(false), new(big.Int).Lsh(big.NewInt(1), 70), 1, true},
+		{"numeric strings by value", "10", "9", 1, true},
+		{"strings", "apple", "banana", -1, true},
+		{"durations", 90 * time.Minute, time.Hour, 1, true},
+		{"duration and string", time.Hour, "90m", -1, true},
+		{"duration strings", "2h", "10m", 1, true},
+		{"times", noon, noon.Add(time.Hour), -1, true},
+		{"time and string", "2024-05-01T13:00:00+01:00", noon, 0, true},
+		{"time strings with offsets", "2024-05-01T13:00:00+02:00", "2024-05-01T12:00:00Z", -1, true},
+		{"pointer", &noon, noon, 0, true},
+		{"time and number", noon, 5, 0, false},
+		{"duration and bad string", time.Hour, "soon", 0, false},
+		{"number and string", 5, "five", 0, false},
+		{"bools", true, false, 0, false},
+		{"nil", nil, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := compareOrdered(tt.leftVal, tt.rightVal)
+			if ok != tt.ok || (ok && c != tt.expected) {
+				t.Errorf("compareOrdered(%v, %v) = %d, %v; want %d, %v", tt.leftVal, tt.rightVal, c, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompareOrderedStrictly(t *testing.T) {
+	tests := []struct {
+		name     string
+		leftVal  any
+		rightVal any
+		expected int
+		ok       bool
+	}{
+		{"numeric strings lexically", "007", "1", -1, true},
+		{"numbers", 10, 9.5, 1, true},
+		{"json number", json.Number("10"), 9, 1, true},
+		{"duration strings", "10m", "9m", 1, true},
+		{"time strings", "2024-05-01T13:00:00+02:00", "2024-05-01T12:00:00Z", -1, true},
+		{"numeric string and number", "10", 9, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := compareOrderedStrictly(tt.leftVal, tt.rightVal)
+			if ok != tt.ok || (ok && c != tt.expected) {
+				t.Errorf("compareOrderedStrictly(%v, %v) = %d, %v; want %d, %v", tt.leftVal, tt.rightVal, c, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestComparisonOperatorsAgreeWithBetween(t *testing.T) {
+	noon := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    any
+		min, max any
+		expected bool
+	}{
+		{"duration strings", "10m", "9m", "1h", true},
+		{"duration string past the bound", "90m", "9m", "1h", false},
+		{"time strings", "2024-05-01T13:00:00+02:00", "2024-05-01T10:00:00Z", "2024-05-01T12:00:00Z", true},
+		{"time strings before the bound", "2024-05-01T11:00:00+02:00", "2024-05-01T10:00:00Z", "2024-05-01T12:00:00Z", false},
+		{"time and strings", noon, "2024-05-01T13:00:00+02:00", "2024-05-01T12:00:00Z", true},
+		{"numeric strings", "10", "9", "100", true},
+		{"strings", "cherry", "apple", "banana", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			between, err := evaluatePolicyCheckOperator("between", tt.value, []any{tt.min, tt.max})
+			if err != nil {
+				t.Fatalf("unexpected error for between: %v", err)
+			}
+
+			atLeast, err := evaluatePolicyCheckOperator(">=", tt.value, tt.min)
+			if err != nil {
+				t.Fatalf("unexpected error for >=: %v", err)
+			}
+
+			atMost, err := evaluatePolicyCheckOperator("<=", tt.value, tt.max)
+			if err != nil {
+				t.Fatalf("unexpected error for <=: %v", err)
+			}
+
+			if between != tt.expected || (atLeast && atMost) != tt.expected {
+				t.Errorf("between = %v, >= and <= = %v; want %v", between, atLeast && atMost, tt.expected)
+			}
+		})
+	}
+}
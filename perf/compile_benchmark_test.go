@@ -0,0 +1,109 @@
+package perf
+
+import (
+	"fmt"
+	"testing"
+
+	pe "github.com/kmesiab/go-policy-enforcer"
+)
+
+type Address struct {
+	City    string
+	Country string
+}
+
+type Account struct {
+	ID      int
+	Age     int
+	Active  bool
+	Plan    string
+	Address *Address
+	Labels  map[string]string
+}
+
+// countries is a large constant right-hand side for the "in" operator
+var countries = func() []string {
+	list := make([]string, 0, 100)
+	for i := 0; i < 99; i++ {
+		list = append(list, fmt.Sprintf("C%02d", i))
+	}
+	return append(list, "US")
+}()
+
+var accountPolicies = []pe.Policy{
+	{
+		Name: "ActiveAdult",
+		Rules: []pe.Rule{
+			{Field: "Active", Operator: "==", Value: true},
+			{Field: "Age", Operator: ">=", Value: 18},
+		},
+	},
+	{
+		Name: "SupportedRegion",
+		Rules: []pe.Rule{
+			{Field: "Address.Country", Operator: "in", Value: countries},
+			{Field: "Labels.team", Operator: "!=", Value: "blocked"},
+		},
+	},
+	{
+		Name: "PaidPlan",
+		Rules: []pe.Rule{
+			{Field: "Plan", Operator: "in", Value: []string{"pro", "enterprise"}},
+		},
+	},
+}
+
+var account = Account{
+	ID:      1,
+	Age:     30,
+	Active:  true,
+	Plan:    "pro",
+	Address: &Address{City: "Seattle", Country: "US"},
+	Labels:  map[string]string{"team": "core"},
+}
+
+// BenchmarkEnforce_Interpreted compiles the policies on every call, as an
+// enforcer without a cache of compiled policies does
+func BenchmarkEnforce_Interpreted(b *testing.B) {
+	enforcer := pe.PolicyEnforcer{Policies: &accountPolicies}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !enforcer.Enforce(account) {
+			b.Fatal("expected account to be allowed")
+		}
+	}
+}
+
+// BenchmarkEnforce_Cached reuses the policies compiled by an enforcer created
+// with NewPolicyEnforcer
+func BenchmarkEnforce_Cached(b *testing.B) {
+	enforcer := pe.NewPolicyEnforcer(&accountPolicies)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !enforcer.Enforce(account) {
+			b.Fatal("expected account to be allowed")
+		}
+	}
+}
+
+func BenchmarkEnforce_Compiled(b *testing.B) {
+	program, err := pe.Compile(accountPolicies, Account{})
+	if err != nil {
+		b.Fatalf("failed to compile policies: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !program.Enforce(account) {
+			b.Fatal("expected account to be allowed")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package perf
+
+import (
+	"fmt"
+	"testing"
+
+	pe "github.com/kmesiab/go-policy-enforcer"
+)
+
+type Request struct {
+	ClientIP     string
+	AgentVersion string
+	Path         string
+	UserAgent    string
+}
+
+// blockedNetworks is a large constant right-hand side for "not_in_cidr"
+var blockedNetworks = func() []string {
+	list := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		list = append(list, fmt.Sprintf("10.%d.%d.0/24", i/256, i%256))
+	}
+	return list
+}()
+
+// requestPolicies use operators whose values are parsed when they are compiled
+var requestPolicies = []pe.Policy{
+	{
+		Name: "Network",
+		Rules: []pe.Rule{
+			{Field: "ClientIP", Operator: "not_in_cidr", Value: blockedNetworks},
+		},
+	},
+	{
+		Name: "Agent",
+		Rules: []pe.Rule{
+			{Field: "AgentVersion", Operator: "semver_satisfies", Value: ">=1.2.0 <2.0.0 || ^3.0.0"},
+			{Field: "UserAgent", Operator: "matches", Value: `^agent/\d+\.\d+$`},
+		},
+	},
+	{
+		Name: "Paths",
+		Rules: []pe.Rule{
+			{Field: "Path", Operator: "glob_any", Value: []string{"projects/*/buckets/public-*/**", "projects/docs/**"}},
+		},
+	},
+}
+
+var request = Request{
+	ClientIP:     "192.168.1.20",
+	AgentVersion: "3.1.4",
+	Path:         "projects/docs/guides/intro.md",
+	UserAgent:    "agent/3.1",
+}
+
+// BenchmarkEnforce_PreparedValues_Interpreted compiles the policies on every call, as an
+// enforcer without a cache of compiled policies does
+func BenchmarkEnforce_PreparedValues_Interpreted(b *testing.B) {
+	enforcer := pe.PolicyEnforcer{Policies: &requestPolicies}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !enforcer.Enforce(request) {
+			b.Fatal("expected request to be allowed")
+		}
+	}
+}
+
+// BenchmarkEnforce_PreparedValues_Cached reuses the policies compiled by an enforcer created
+// with NewPolicyEnforcer
+func BenchmarkEnforce_PreparedValues_Cached(b *testing.B) {
+	enforcer := pe.NewPolicyEnforcer(&requestPolicies)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !enforcer.Enforce(request) {
+			b.Fatal("expected request to be allowed")
+		}
+	}
+}
+
+func BenchmarkEnforce_PreparedValues_Compiled(b *testing.B) {
+	program, err := pe.Compile(requestPolicies, Request{})
+	if err != nil {
+		b.Fatalf("failed to compile policies: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !program.Enforce(request) {
+			b.Fatal("expected request to be allowed")
+		}
+	}
+}
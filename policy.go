@@ -1,13 +1,26 @@
 package go_policy_enforcer
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"reflect"
-	"strconv"
-	"strings"
+)
+
+// Effect is what a policy decides when a resource satisfies its rules.
+type Effect string
+
+const (
+	// EffectAllow permits resources that satisfy the policy's rules. The policy
+	// is not applicable to resources that do not.
+	EffectAllow Effect = "allow"
+
+	// EffectDeny denies resources that satisfy the policy's rules. The policy
+	// is not applicable to resources that do not.
+	EffectDeny Effect = "deny"
 )
 
 // Policy represents a set of rules that define access control or behavior.
@@ -15,163 +28,396 @@ import (
 //
 // The Policy struct has the following fields:
 // - Name: A string representing the name of the policy.
+// - Effect: The outcome when the rules are satisfied. When empty, the policy is a constraint
+// that permits resources satisfying its rules and denies all others.
+// - Target: Rules that decide whether the policy applies to a resource at all. A policy with
+// no target applies to every resource.
 // - Rules: A slice of Rule structs representing the rules that define the policy.
+// - StrictTypes: Compare values without coercing them, so the string "007" is not equal
+// to the number 7, and fail rules comparing values of incompatible types. See WithStrictTypes.
 type Policy struct {
-	Name  string
-	Rules []Rule
+	Name        string `json:"name"`
+	Effect      Effect `json:"effect,omitempty"`
+	Target      []Rule `json:"target,omitempty"`
+	Rules       []Rule `json:"rules"`
+	StrictTypes bool   `json:"strictTypes,omitempty"`
 }
 
 // Evaluate checks if the given resource adheres to the policy's rules.
-// The resource must be a struct, and its fields are evaluated against the policy's rules.
-// If any rule fails, the function returns false. Otherwise, it returns true.
+// The resource must be a struct, or an AccessRequest, and its fields are evaluated against the policy's rules.
+// If the policy's target does not match or any rule fails, the function returns false.
+// Otherwise, it returns true.
+//
+// Evaluate reports whether the rules matched, not whether the resource is allowed:
+// for a policy with EffectDeny, true means the resource matched the deny condition.
+//
+// Each call compiles the rules it reaches. To evaluate a policy against many
+// resources, use a PolicyEnforcer, which compiles it once, or Compile.
 //
 // Parameters:
-// - resource: The resource to be evaluated. It must be a struct.
+// - resource: The resource to be evaluated. It must be a struct or an AccessRequest.
 //
 // Return:
 // - bool: Returns true if the resource adheres to all policy rules, false otherwise.
 func (p *Policy) Evaluate(resource any) bool {
-	v := reflect.ValueOf(resource)
+	return p.evaluate(context.Background(), resource, false).Passed
+}
+
+// EvaluateE checks if the given resource adheres to the policy's rules, like
+// Evaluate, but reports why evaluation could not be completed instead of
+// treating every failure as a non-match.
+//
+// A resource that simply does not satisfy the rules returns false and a nil
+// error. When a rule cannot be evaluated, for example because its field does
+// not exist or its operator is unknown, EvaluateE returns false and a *RuleError
+// wrapping one of ErrFieldNotFound, ErrUnexportedField, ErrOperatorNotSupported,
+// ErrTypeMismatch, ErrIndexOutOfRange or ErrInvalidRule. A resource that is not a struct returns
+// an error wrapping ErrTypeMismatch.
+//
+// Parameters:
+// - resource: The resource to be evaluated. It must be a struct or an AccessRequest.
+//
+// Return:
+// - bool: Returns true if the resource adheres to all policy rules, false otherwise.
+// - error: An error if any rule could not be evaluated.
+func (p *Policy) EvaluateE(resource any) (bool, error) {
+	result := p.evaluate(context.Background(), resource, false)
+	return result.Passed, result.Err
+}
+
+// evaluate checks the resource against every rule in the policy and returns the
+// outcome as a PolicyResult. When explain is false evaluation stops at the first
+// failing rule and no per-rule results are recorded; when it is true every rule
+// is evaluated so the result can be used to explain the decision.
+//
+// The policy is compiled on every call so changes to it are always honoured.
+// Problems found while compiling are reported when the affected rule is reached.
+// PolicyEnforcer keeps compiled policies instead; see policyCache.
+//
+// Evaluation stops when ctx is done, and the result's error is then ctx.Err().
+func (p *Policy) evaluate(ctx context.Context, resource any, explain bool) PolicyResult {
+	cp, _ := lenientCompiler.compilePolicy(p, nil)
+	return cp.evaluate(ctx, resource, explain)
+}
+
+// evaluate checks the resource against the compiled policy's target and rules.
+func (cp *compiledPolicy) evaluate(ctx context.Context, resource any, explain bool) PolicyResult {
+	result := PolicyResult{Policy: cp.policy}
+
+	v, ok := resourceRootValue(resource)
+	if !ok {
+		result.Outcome = OutcomeIndeterminate
+		result.Err = newEvaluationError(ErrTypeMismatch, "resource of type %T is not a struct", resource)
+		return result
+	}
+
+	if cp.err != nil {
+		result.Outcome, result.Err = OutcomeIndeterminate, cp.err
+		return result
+	}
 
-	// Handle pointers by dereferencing them
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
+	// A policy only applies when every target rule passes
+	applies, targetResults, err := cp.evaluateRules(ctx, v, cp.policy.Target, cp.target, explain)
+	result.Target = targetResults
+
+	switch {
+	case err != nil:
+		result.Outcome, result.Err = OutcomeIndeterminate, err
+		return result
+	case !applies:
+		result.Outcome = OutcomeNotApplicable
+		return result
 	}
 
-	// Ensure we're working with a struct
-	if v.Kind() != reflect.Struct {
-		return false
+	result.Passed, result.Rules, result.Err = cp.evaluateRules(ctx, v, cp.policy.Rules, cp.rules, explain)
+
+	effect := cp.policy.Effect
+
+	switch {
+	case result.Err != nil:
+		result.Outcome = OutcomeIndeterminate
+	case result.Passed && effect == EffectDeny:
+		result.Outcome = OutcomeDeny
+	case result.Passed:
+		result.Outcome = OutcomePermit
+	case effect == "":
+		result.Outcome = OutcomeDeny
+	default:
+		result.Outcome = OutcomeNotApplicable
 	}
 
-	for _, rule := range p.Rules {
-		// Handle nested rules
-		if nestedRules, ok := rule.Value.([]Rule); ok {
-			fieldValue, err := getNestedField(v, rule.Field)
-			if err != nil {
-				return false
+	return result
+}
+
+// evaluateRules reports whether every rule passes against v. When explain is
+// false evaluation stops at the first failing rule and no results are kept.
+// The returned error is a *RuleError for the first failing rule when it failed
+// with an error, or ctx.Err() when ctx is done before every rule has been
+// evaluated. When explain is true the rules after the first failing rule are
+// still evaluated and recorded, with their errors in their results, but they do
+// not change what is returned, so the outcome is the same as without explain.
+//
+// Rules are taken from compiled when it is not nil, and are otherwise compiled
+// from source as they are reached.
+func (cp *compiledPolicy) evaluateRules(ctx context.Context, v reflect.Value, source []Rule, compiled []*compiledRule, explain bool) (bool, []RuleResult, error) {
+	var (
+		passed  = true
+		results []RuleResult
+		err     error
+	)
+
+	for i := range source {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return false, results, ctxErr
+		}
+
+		var rule *compiledRule
+		if compiled != nil {
+			rule = compiled[i]
+		} else {
+			rule, _ = cp.compiler.compileRule(&source[i], nil)
+		}
+
+		ruleResult := rule.evaluate(ctx, v, explain)
+
+		if explain {
+			results = append(results, ruleResult)
+		}
+
+		if !ruleResult.Passed {
+			// The rule was interrupted rather than failing on its own
+			if ctxErr := contextError(ctx); ruleResult.Err != nil && ctxErr != nil {
+				return false, results, ctxErr
 			}
 
-			if fieldValue.Kind() == reflect.Slice {
-				matched := false
-				for i := 0; i < fieldValue.Len(); i++ {
-					elem := fieldValue.Index(i).Interface()
-					for _, nestedRule := range nestedRules {
-
-						ok, err := evaluatePolicyCheckOperator(
-							nestedRule.Operator,
-							reflect.ValueOf(elem).FieldByName(nestedRule.Field).Interface(),
-							nestedRule.Value,
-						)
-
-						if err != nil {
-							log.Println(err)
-							return false
-						}
-
-						// Perform the pattern match
-						if ok {
-
-							matched = true
-							break
-						}
-					}
-					if matched {
-						break
-					}
-				}
-				if !matched {
-					return false
-				}
-				continue
+			// Only the first failing rule decides the result
+			if passed && ruleResult.Err != nil {
+				err = &RuleError{Policy: cp.policy.Name, Rule: source[i], Err: ruleResult.Err}
+			}
+
+			passed = false
+
+			if !explain {
+				break
 			}
 		}
+	}
+
+	return passed, results, err
+}
+
+// evaluate resolves the rule's field on v and compares it using the rule's
+// operator. Rules whose value is a []Rule are treated as nested rules and are
+// matched against each element of the slice the field resolves to, and
+// condition groups are handed to evaluateGroup.
+func (r *compiledRule) evaluate(ctx context.Context, v reflect.Value, explain bool) RuleResult {
+	if r.group != "" {
+		return r.evaluateGroup(ctx, v, explain)
+	}
+
+	result := RuleResult{
+		Field:    r.rule.Field,
+		Operator: r.rule.Operator,
+		Expected: r.rule.Value,
+	}
+
+	if r.err != nil {
+		result.Err = r.err
+		return result
+	}
+
+	fieldValue, err := r.path.resolve(v)
+	if err != nil {
+		// Operators such as "exists" treat a missing field as an outcome
+		if r.operator.missingFields && isMissingFieldError(err) {
+			result.Passed, result.Err = r.compare(ctx, missingField{}, r.value)
+			return result
+		}
+
+		result.Err = err
+		return result
+	}
+
+	if !fieldValue.CanInterface() {
+		return result
+	}
+
+	result.Value = fieldValue.Interface()
 
-		// Handle regular policy checks
-		fieldValue, err := getNestedField(v, rule.Field)
+	// Handle nested rules
+	if r.nested != nil && fieldValue.Kind() == reflect.Slice {
+		result.Passed, result.Rules, result.Err = r.evaluateNestedRules(ctx, fieldValue, explain)
+		return result
+	}
+
+	rightVal := r.value
+
+	// Compare against another field of the resource
+	if r.ref != nil {
+		refValue, err := r.ref.resolve(v)
 		if err != nil {
-			return false
+			result.Err = err
+			return result
 		}
 
-		if !fieldValue.CanInterface() {
-			return false
+		rightVal = refValue.Interface()
+		result.Expected = rightVal
+		result.ExpectedField = r.ref.raw
+	}
+
+	// Handle regular policy checks
+	result.Passed, result.Err = r.compare(ctx, result.Value, rightVal)
+
+	return result
+}
+
+// compare applies the rule's operator to the resolved field value and the value
+// it is compared against, using the prepared comparison when the rule was
+// compiled with one.
+func (r *compiledRule) compare(ctx context.Context, leftVal, rightVal any) (bool, error) {
+	if r.operatorErr != nil {
+		return false, r.operatorErr
+	}
+
+	if r.prepared != nil {
+		return r.prepared(ctx, leftVal)
+	}
+
+	return applyTypedPolicyCheckOperator(ctx, r.rule.Operator, r.operator, r.strictTypes, leftVal, rightVal)
+}
+
+// evaluateGroup evaluates an all, any or not condition group against v. The
+// group short-circuits: all stops at the first failing rule and any stops at the
+// first passing rule. An error from any rule, or ctx being done, stops evaluation
+// and fails the group. When explain is true the remaining rules are still
+// evaluated so their results are recorded, but they do not change the group's
+// result.
+func (r *compiledRule) evaluateGroup(ctx context.Context, v reflect.Value, explain bool) RuleResult {
+	result := RuleResult{Operator: r.group}
+
+	if r.err != nil {
+		result.Err = r.err
+		return result
+	}
+
+	// Evaluation stops when a child produces this result
+	stopOn := r.group != allGroupOperator
+
+	// An empty all passes and an empty any fails
+	result.Passed = !stopOn
+
+	decided := false
+
+	for _, child := range r.children {
+		if err := contextError(ctx); err != nil {
+			result.Passed, result.Err = false, err
+			return result
 		}
 
-		ok, err := evaluatePolicyCheckOperator(rule.Operator, fieldValue.Interface(), rule.Value)
+		childResult := child.evaluate(ctx, v, explain)
+
+		if explain {
+			result.Rules = append(result.Rules, childResult)
+		}
+
+		switch {
+		case decided:
+			continue
+		case childResult.Err != nil:
+			result.Passed, result.Err = false, childResult.Err
+			decided = true
+		case childResult.Passed == stopOn:
+			result.Passed = stopOn
+			decided = true
+		}
 
-		if err != nil || !ok {
-			return false
+		if decided && !explain {
+			break
 		}
 	}
 
-	return true
+	if r.group == notGroupOperator && result.Err == nil {
+		result.Passed = !result.Passed
+	}
+
+	return result
 }
 
-func getNestedField(v reflect.Value, fieldPath string) (reflect.Value, error) {
-	fields := strings.Split(fieldPath, ".")
-	for i, field := range fields {
-		// Handle map access
-		if v.Kind() == reflect.Map {
-			key := reflect.ValueOf(field)
-			v = v.MapIndex(key)
-			if !v.IsValid() {
-				return reflect.Value{}, fmt.Errorf("key %s not found in map", field)
-			}
-		} else {
-			// Handle slice indexing (e.g., Field[0])
-			if strings.Contains(field, "[") && strings.Contains(field, "]") {
-				parts := strings.Split(field, "[")
-				fieldName := parts[0]
-				indexStr := strings.TrimSuffix(parts[1], "]")
-
-				// Get the field by name
-				v = v.FieldByName(fieldName)
-				if !v.IsValid() {
-					return reflect.Value{}, fmt.Errorf("field %s not found", fieldName)
-				}
+// evaluateNestedRules reports whether any element of slice satisfies any of the
+// nested rules. The field paths of the returned results are prefixed with the
+// indexed element they were evaluated against, e.g. "Items[2].Status".
+//
+// Evaluation stops at the first nested rule that passes or returns an error,
+// and that error is returned. When explain is true the remaining elements are
+// still evaluated so their results are recorded, but they do not change the
+// outcome. ctx is checked before each element, so large slices can be
+// abandoned part way through.
+func (r *compiledRule) evaluateNestedRules(ctx context.Context, slice reflect.Value, explain bool) (bool, []RuleResult, error) {
+	var (
+		passed  bool
+		results []RuleResult
+		err     error
+	)
 
-				// Ensure it's a slice or array
-				if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
-					return reflect.Value{}, fmt.Errorf("field %s is not a slice or array", fieldName)
-				}
+	for i := 0; i < slice.Len(); i++ {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return false, results, ctxErr
+		}
 
-				// Convert the index to an integer
-				index, err := strconv.Atoi(indexStr)
-				if err != nil {
-					return reflect.Value{}, fmt.Errorf("invalid slice index %s", indexStr)
-				}
+		elem := reflect.Indirect(reflect.ValueOf(slice.Index(i).Interface()))
 
-				// Check if the index is within bounds
-				if index >= v.Len() {
-					return reflect.Value{}, fmt.Errorf("index %d out of bounds for slice %s", index, fieldName)
-				}
+		for _, nestedRule := range r.nested {
+			ruleResult := nestedRule.evaluate(ctx, elem, explain)
 
-				// Get the indexed value
-				v = v.Index(index)
-			} else {
-				// Regular struct field access
-				v = v.FieldByName(field)
-				if !v.IsValid() {
-					return reflect.Value{}, fmt.Errorf("field %s not found", field)
+			if explain {
+				ruleResult.Field = fmt.Sprintf("%s[%d].%s", r.rule.Field, i, nestedRule.rule.Field)
+				if ruleResult.ExpectedField != "" {
+					ruleResult.ExpectedField = fmt.Sprintf("%s[%d].%s", r.rule.Field, i, ruleResult.ExpectedField)
 				}
+				results = append(results, ruleResult)
 			}
-		}
 
-		// Check if the field is exported (CanInterface returns false for unexported fields)
-		if !v.CanInterface() {
-			return reflect.Value{}, fmt.Errorf("field %s is unexported and cannot be accessed", field)
-		}
+			if passed || err != nil {
+				continue
+			}
+
+			// Perform the pattern match
+			if ruleResult.Err != nil {
+				err = ruleResult.Err
+			} else {
+				passed = ruleResult.Passed
+			}
 
-		// Only dereference if this is not the last field in the path
-		if v.Kind() == reflect.Ptr && !v.IsNil() && i < len(fields)-1 {
-			v = v.Elem()
+			if (passed || err != nil) && !explain {
+				return passed, results, err
+			}
 		}
 	}
-	return v, nil
+
+	return passed, results, err
+}
+
+// getNestedField resolves a dot separated field path, such as "Address.City",
+// "Items[0].Name" or "Labels.team", against v. Struct fields, map keys and
+// slice or array indexes are supported, and pointers are followed along the way.
+//
+// Errors returned by getNestedField wrap ErrFieldNotFound, ErrUnexportedField,
+// ErrTypeMismatch or ErrIndexOutOfRange.
+func getNestedField(v reflect.Value, fieldPath string) (reflect.Value, error) {
+	return parseFieldPath(fieldPath).resolve(v)
 }
 
 // LoadPolicy reads a policy from a JSON file and returns a Policy struct.
 // If the file cannot be read or the JSON is invalid, an error is returned.
 //
+// The policy is also checked for malformed rules, such as an invalid "matches"
+// pattern or condition group, which are returned as a *RuleError wrapping
+// ErrInvalidRule. Operators that are not registered are not reported, as they
+// may be registered after the policy is loaded.
+//
+// Numbers in rule values are decoded as json.Number, so that amounts such as
+// 19.99 are compared with decimal fields exactly rather than as a float64.
+//
 // Parameters:
 // - policyFile: A string representing the path to the policy JSON file.
 //
@@ -191,26 +437,33 @@ func LoadPolicy(policyFile string) (*Policy, error) {
 	}
 
 	// Invalid policy JSON
-	if err = json.Unmarshal(policyString, policy); err != nil {
+	if err = decodePolicy(policyString, policy); err != nil {
 		return nil, fmt.Errorf("invalid policy json: %v", err)
 	}
 
+	// Malformed rules
+	if _, err = loadCompiler.compilePolicy(policy, nil); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
 	return policy, nil
 }
 
-// getPolicyCheckOperator retrieves a PolicyCheckOperator function based on the given operator string.
-// It uses a map of predefined operators to find the corresponding function.
-//
-// Parameters:
-// - operator: A string representing the operator to retrieve the function for.
-//
-// Return:
-// - PolicyCheckOperator[any]: A function that performs the policy check operation.
-// - error: An error if the operator does not exist in the predefined map.
-func getPolicyCheckOperator(operator string) (PolicyCheckOperator[any], error) {
-	if fn, ok := policyCheckOperatorMap[operator]; ok {
-		return fn, nil
+// decodePolicy decodes a JSON policy into policy. Numbers are decoded as
+// json.Number rather than float64, so decimal values such as 19.99 are compared
+// exactly and large integers keep every digit.
+func decodePolicy(data []byte, policy *Policy) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(policy); err != nil {
+		return err
+	}
+
+	// Anything after the policy is as invalid as it is to json.Unmarshal
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the policy")
 	}
 
-	return nil, fmt.Errorf("operator %s does not exist", operator)
+	return nil
 }
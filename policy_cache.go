@@ -0,0 +1,87 @@
+package go_policy_enforcer
+
+import (
+	"sync"
+)
+
+// policyCache keeps the compiled form of each of an enforcer's policies, so the
+// field paths, operators and values of their rules are parsed, looked up and
+// prepared once rather than on every call. A policy is compiled again when it
+// is replaced, when an operator has been registered or unregistered, or when it
+// is evaluated with another registry or strict types setting.
+//
+// Policies are recognised by their address, which is all that is checked on
+// each call, so that the check costs the same however large the policies are.
+// A policy that is changed in place, such as by giving one of its rules a new
+// value, is not noticed; replace the enforcer's slice of policies instead.
+type policyCache struct {
+	mu      sync.RWMutex
+	entries []*cachedPolicy
+}
+
+// cachedPolicy is a compiled policy together with what it was compiled from.
+type cachedPolicy struct {
+	policy      *Policy
+	operators   *OperatorRegistry
+	generation  uint64
+	strictTypes bool
+	compiled    *compiledPolicy
+}
+
+// newPolicyCache creates an empty policyCache.
+func newPolicyCache() *policyCache {
+	return &policyCache{}
+}
+
+// compiledPolicy returns policies[i] compiled with c, from the cache when it is
+// still up to date. A nil cache compiles the policy on every call.
+func (pc *policyCache) compiledPolicy(c compiler, policies []Policy, i int) *compiledPolicy {
+	p := &policies[i]
+
+	if pc == nil {
+		cp, _ := c.compilePolicy(p, nil)
+		return cp
+	}
+
+	generation := c.operators.currentGeneration()
+
+	var entry *cachedPolicy
+
+	pc.mu.RLock()
+	if i < len(pc.entries) {
+		entry = pc.entries[i]
+	}
+	pc.mu.RUnlock()
+
+	if entry != nil && entry.upToDate(c, p, generation) {
+		return entry.compiled
+	}
+
+	entry = &cachedPolicy{
+		policy:      p,
+		operators:   c.operators,
+		generation:  generation,
+		strictTypes: c.strictTypes,
+	}
+
+	c.eager, c.prepare = true, true
+	entry.compiled, _ = c.compilePolicy(p, nil)
+
+	pc.mu.Lock()
+	if len(pc.entries) < len(policies) {
+		pc.entries = append(pc.entries, make([]*cachedPolicy, len(policies)-len(pc.entries))...)
+	}
+	pc.entries[i] = entry
+	pc.mu.Unlock()
+
+	return entry.compiled
+}
+
+// upToDate reports whether the entry was compiled from p with the same settings
+// as c and the registry at generation.
+func (entry *cachedPolicy) upToDate(c compiler, p *Policy, generation uint64) bool {
+	return entry.policy == p &&
+		entry.operators == c.operators &&
+		entry.generation == generation &&
+		entry.strictTypes == c.strictTypes
+}
@@ -0,0 +1,171 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPolicyEnforcer_RecompilesReplacedPolicies(t *testing.T) {
+	type group struct {
+		Name string
+	}
+
+	type user struct {
+		Name   string
+		Age    int
+		Roles  []string
+		Groups []group
+	}
+
+	resource := user{Name: "alice", Age: 30, Roles: []string{"admin"}, Groups: []group{{Name: "staff"}}}
+
+	tests := []struct {
+		name     string
+		change   func(policies *[]Policy)
+		expected bool
+		matched  bool
+	}{
+		{"value", func(p *[]Policy) { (*p)[0].Rules[0].Value = "bob" }, false, false},
+		{"operator", func(p *[]Policy) { (*p)[0].Rules[0].Operator = "!=" }, false, false},
+		{"field", func(p *[]Policy) { (*p)[0].Rules[0].Field = "Roles" }, false, false},
+		{"list value", func(p *[]Policy) { (*p)[0].Rules[1].Value = []string{"guest"} }, false, false},
+		{"added rule", func(p *[]Policy) {
+			(*p)[0].Rules = append((*p)[0].Rules, Rule{Field: "Age", Operator: "<", Value: 18})
+		}, false, false},
+		{"target", func(p *[]Policy) { (*p)[0].Target = []Rule{{Field: "Age", Operator: "<", Value: 18}} }, false, false},
+		{"effect", func(p *[]Policy) { (*p)[0].Effect = EffectDeny }, false, true}, // The rules still match
+		{"strict types", func(p *[]Policy) { (*p)[0].Rules[0].Value = 7; (*p)[0].StrictTypes = true }, false, false},
+		{"nested rule", func(p *[]Policy) { (*p)[0].Rules[2].Value.([]Rule)[0].Value = "g" }, false, false},
+		{"group", func(p *[]Policy) { (*p)[0].Rules[3].Not.Value = 30 }, false, false},
+		{"replaced policy", func(p *[]Policy) {
+			*p = []Policy{{Name: "Adults", Rules: []Rule{{Field: "Age", Operator: ">=", Value: 40}}}}
+		}, false, false},
+		{"added policy", func(p *[]Policy) {
+			*p = append(*p, Policy{Name: "Adults", Rules: []Rule{{Field: "Age", Operator: ">=", Value: 40}}})
+		}, false, true},
+		{"unchanged", func(p *[]Policy) { (*p)[0].Rules[0].Value = "alice" }, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := &[]Policy{
+				{
+					Name: "Admins",
+					Rules: []Rule{
+						{Field: "Name", Operator: "==", Value: "alice"},
+						{Field: "Roles", Operator: "contains_any", Value: []string{"admin"}},
+						{Field: "Groups", Operator: "any", Value: []Rule{{Field: "Name", Operator: "==", Value: "staff"}}},
+						{Not: &Rule{Field: "Age", Operator: "==", Value: 12}},
+					},
+				},
+			}
+
+			enforcer := NewPolicyEnforcer(policies)
+
+			if result, err := enforcer.EnforceE(resource); !result || err != nil {
+				t.Fatalf("expected (true, nil) before the change, but got (%v, %v)", result, err)
+			}
+
+			// Policies are changed by replacing them, not in place
+			updated := append([]Policy(nil), *policies...)
+			tt.change(&updated)
+			*policies = updated
+
+			if result := enforcer.Enforce(resource); result != tt.expected {
+				t.Errorf("expected %v after the change, but got %v", tt.expected, result)
+			}
+
+			if result := len(enforcer.Match(resource)) == 1; result != tt.matched {
+				t.Errorf("expected Match to report %v after the change, but got %v", tt.matched, result)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_RecompilesWhenOperatorsChange(t *testing.T) {
+	registry := NewOperatorRegistry()
+
+	policies := &[]Policy{
+		{Name: "Prefix", Rules: []Rule{{Field: "Name", Operator: "prefix", Value: "po"}}},
+	}
+
+	enforcer := NewPolicyEnforcer(policies, WithOperatorRegistry(registry))
+	resource := struct{ Name string }{Name: "policy"}
+
+	if _, err := enforcer.EnforceE(resource); !errors.Is(err, ErrOperatorNotSupported) {
+		t.Fatalf("expected ErrOperatorNotSupported, but got %v", err)
+	}
+
+	prefix := func(leftVal, rightVal any) bool {
+		return strings.HasPrefix(leftVal.(string), rightVal.(string))
+	}
+
+	if err := registry.Register("prefix", prefix); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result, err := enforcer.EnforceE(resource); !result || err != nil {
+		t.Errorf("expected (true, nil) once the operator is registered, but got (%v, %v)", result, err)
+	}
+
+	registry.Unregister("prefix")
+
+	if _, err := enforcer.EnforceE(resource); !errors.Is(err, ErrOperatorNotSupported) {
+		t.Errorf("expected ErrOperatorNotSupported once the operator is unregistered, but got %v", err)
+	}
+}
+
+func TestPolicyEnforcer_CachedPoliciesFollowEnforcerSettings(t *testing.T) {
+	policies := &[]Policy{
+		{Name: "Age", Rules: []Rule{{Field: "Age", Operator: "==", Value: "30"}}},
+	}
+
+	resource := struct{ Age int }{Age: 30}
+
+	enforcer := NewPolicyEnforcer(policies).(PolicyEnforcer)
+	if !enforcer.Enforce(resource) {
+		t.Fatalf("expected the string \"30\" to equal 30 without strict types")
+	}
+
+	// A copy of the enforcer shares its cache, but not its settings
+	strict := enforcer
+	strict.StrictTypes = true
+
+	if _, err := strict.EnforceE(resource); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch with strict types, but got %v", err)
+	}
+
+	if !enforcer.Enforce(resource) {
+		t.Errorf("expected the original enforcer to still pass")
+	}
+}
+
+func TestPolicyEnforcer_ConcurrentCachedUse(t *testing.T) {
+	policies := &[]Policy{
+		{Name: "Country", Rules: []Rule{{Field: "Country", Operator: "in", Value: []string{"US", "CA"}}}},
+		{Name: "Age", Rules: []Rule{{Field: "Age", Operator: ">=", Value: 18}}},
+	}
+
+	enforcer := NewPolicyEnforcer(policies)
+	resource := struct {
+		Country string
+		Age     int
+	}{Country: "CA", Age: 30}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			if !enforcer.Enforce(resource) || len(enforcer.Decide(resource).Policies) != 2 {
+				t.Errorf("expected every policy to pass")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
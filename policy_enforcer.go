@@ -1,13 +1,104 @@
 package go_policy_enforcer
 
+import (
+	"context"
+	"time"
+)
+
 type PolicyEnforcerInterface interface {
 	Enforce(resource any) bool
+	EnforceE(resource any) (bool, error)
+	EnforceContext(ctx context.Context, resource any) (bool, error)
 	Match(resource any) []*Policy
+	MatchContext(ctx context.Context, resource any) ([]*Policy, error)
+	Decide(resource any) Decision
 }
 
 type PolicyEnforcer struct {
 	PolicyEnforcerInterface
 	Policies *[]Policy
+
+	// Algorithm combines the outcomes of the policies. The zero value selects DenyOverrides.
+	Algorithm CombiningAlgorithm
+
+	// Operators resolves the operators used by rules. Nil selects DefaultOperatorRegistry.
+	Operators *OperatorRegistry
+
+	// Clock returns the current time for operators such as within. Nil selects time.Now.
+	Clock func() time.Time
+
+	// StrictTypes compares values without coercing them, for every policy. See WithStrictTypes.
+	StrictTypes bool
+
+	// cache holds the compiled policies of an enforcer created by
+	// NewPolicyEnforcer. Without it, policies are compiled on every call.
+	cache *policyCache
+}
+
+// PolicyEnforcerOption configures a PolicyEnforcer created by NewPolicyEnforcer.
+type PolicyEnforcerOption func(*PolicyEnforcer)
+
+// WithCombiningAlgorithm selects how the enforcer combines the outcomes of its
+// policies. Without this option, DenyOverrides is used, so every policy must pass.
+//
+// Parameters:
+// - algorithm: The CombiningAlgorithm to use.
+//
+// Returns:
+// - PolicyEnforcerOption: An option to pass to NewPolicyEnforcer.
+func WithCombiningAlgorithm(algorithm CombiningAlgorithm) PolicyEnforcerOption {
+	return func(e *PolicyEnforcer) {
+		e.Algorithm = algorithm
+	}
+}
+
+// WithOperatorRegistry makes the enforcer look up rule operators in registry
+// instead of DefaultOperatorRegistry. Enforcers with different registries can
+// support different operators.
+//
+// Parameters:
+// - registry: The OperatorRegistry to use.
+//
+// Returns:
+// - PolicyEnforcerOption: An option to pass to NewPolicyEnforcer.
+func WithOperatorRegistry(registry *OperatorRegistry) PolicyEnforcerOption {
+	return func(e *PolicyEnforcer) {
+		e.Operators = registry
+	}
+}
+
+// WithClock makes the enforcer read the current time from clock, instead of
+// time.Now, when evaluating time-relative rules such as "within 24h" or
+// "before now". Use it to make such rules testable, or to evaluate them as of
+// another time.
+//
+// Parameters:
+// - clock: A function returning the current time.
+//
+// Returns:
+// - PolicyEnforcerOption: An option to pass to NewPolicyEnforcer.
+func WithClock(clock func() time.Time) PolicyEnforcerOption {
+	return func(e *PolicyEnforcer) {
+		e.Clock = clock
+	}
+}
+
+// WithStrictTypes makes the enforcer compare values without coercing them, so
+// the string "007" is no longer equal to the number 7 and "1e3" is no longer
+// 1000. Rules comparing values of incompatible kinds, such as a string with a
+// number, fail with an error wrapping ErrTypeMismatch instead of passing or
+// failing silently. Numbers of different types are still compared by value,
+// and times and durations can still be written as strings.
+//
+// Strict types can also be enabled for a single policy with Policy.StrictTypes.
+// Use StrictTypesReport to find the rules whose results would change.
+//
+// Returns:
+// - PolicyEnforcerOption: An option to pass to NewPolicyEnforcer.
+func WithStrictTypes() PolicyEnforcerOption {
+	return func(e *PolicyEnforcer) {
+		e.StrictTypes = true
+	}
 }
 
 // NewPolicyEnforcer creates a new instance of PolicyEnforcer with the provided
@@ -22,48 +113,89 @@ type PolicyEnforcer struct {
 // Parameters:
 // - policies: A pointer to a slice of Policy structs. Each Policy represents a
 // set of rules or conditions that need to be enforced.
+// - options: Optional settings, such as WithCombiningAlgorithm, WithOperatorRegistry, WithClock or WithStrictTypes.
+//
+// The enforcer compiles each policy the first time it is evaluated and reuses
+// the compiled policy until the policy is replaced or an operator is registered
+// or unregistered. Policies must not be changed in place once they have been
+// evaluated; to change them, point the slice at new policies, as in
+// *policies = updated.
 //
 // Returns:
 // - PolicyEnforcerInterface: An interface that provides the Enforce method to
 // check if a resource complies with the policies.
-func NewPolicyEnforcer(policies *[]Policy) PolicyEnforcerInterface {
-	return PolicyEnforcer{
+func NewPolicyEnforcer(policies *[]Policy, options ...PolicyEnforcerOption) PolicyEnforcerInterface {
+	enforcer := PolicyEnforcer{
 		Policies: policies,
+		cache:    newPolicyCache(),
+	}
+
+	for _, option := range options {
+		option(&enforcer)
 	}
+
+	return enforcer
 }
 
-// Enforce checks if a given resource complies with all the policies.
+// Enforce checks if a given resource complies with the policies.
 //
-// The function iterates over each policy in the PolicyEnforcer's policies slice.
+// The function evaluates each policy in the PolicyEnforcer's policies slice and
+// combines their outcomes using the enforcer's CombiningAlgorithm. With the
+// default, DenyOverrides, every policy must pass: evaluation stops at the first
+// policy that fails and Enforce returns false.
 //
-// For each policy, it calls the Evaluate method with the provided resource.
-//
-// If the Evaluate method returns false, the function immediately returns false,
-// indicating that the resource does not comply with the policy.
-//
-// If the Evaluate method returns true for all policies, the function returns true,
-// indicating that the resource complies with all policies.
+// If there are no policies, Enforce returns false, as no policy can be enforced.
 //
 // Parameters:
 // - resource: The resource to be evaluated against the policies. The type can be any valid Go type.
 //
 // Returns:
-// - bool: A boolean value indicating whether the resource complies with all the policies.
-//   - true: The resource complies with all the policies.
-//   - false: The resource does not comply with at least one policy.
+// - bool: A boolean value indicating whether the resource complies with the policies.
+//   - true: The combined outcome of the policies is OutcomePermit.
+//   - false: The combined outcome is a deny, not applicable or indeterminate.
 func (e PolicyEnforcer) Enforce(resource any) bool {
+	return e.decide(context.Background(), resource, false).Allowed
+}
 
-	// If there are no policies, return false immediately, as no policy can be enforced.
-	if e.Policies == nil || len(*e.Policies) == 0 {
-		return false
-	}
+// EnforceE checks if a given resource complies with the policies, like
+// Enforce, but returns an error when no decision could be reached instead of
+// treating it as a denial.
+//
+// A nil error with a false result means the policies denied the resource. An
+// error is returned when the combined outcome is OutcomeIndeterminate: it is a
+// *RuleError for a policy that failed to evaluate, wraps ErrTypeMismatch when the
+// resource is not a struct, or wraps ErrPolicyConflict or
+// ErrInvalidCombiningAlgorithm; use errors.Is to test for the sentinel errors.
+//
+// Parameters:
+// - resource: The resource to be evaluated against the policies. The type can be any valid Go type.
+//
+// Returns:
+// - bool: A boolean value indicating whether the resource complies with the policies.
+// - error: An error if no decision could be reached.
+func (e PolicyEnforcer) EnforceE(resource any) (bool, error) {
+	return e.EnforceContext(context.Background(), resource)
+}
 
-	for _, p := range *e.Policies {
-		if !p.Evaluate(resource) {
-			return false
-		}
-	}
-	return true
+// EnforceContext checks if a given resource complies with the policies, like
+// EnforceE, but stops evaluating as soon as ctx is cancelled or its deadline
+// passes. ctx is checked between policies, between rules and between the
+// elements of slices matched by nested rules, and is passed to any
+// ContextPolicyCheckOperator so it can read request-scoped values.
+//
+// When evaluation is stopped by ctx, EnforceContext returns false and ctx.Err(),
+// unwrapped, so it can be told apart from both a denial and a policy error.
+//
+// Parameters:
+// - ctx: The context that bounds the evaluation.
+// - resource: The resource to be evaluated against the policies. The type can be any valid Go type.
+//
+// Returns:
+// - bool: A boolean value indicating whether the resource complies with the policies.
+// - error: ctx.Err() if evaluation was stopped, or the error EnforceE would return.
+func (e PolicyEnforcer) EnforceContext(ctx context.Context, resource any) (bool, error) {
+	decision := e.decide(ctx, resource, false)
+	return decision.Allowed, decision.Err
 }
 
 // Match checks if a given resource matches any of the policies and returns a slice of matching policies.
@@ -79,13 +211,95 @@ func (e PolicyEnforcer) Enforce(resource any) bool {
 //   - []*Policy: A slice of pointers to policies that match the provided resource.
 //     If no policies match, an empty slice is returned.
 func (e PolicyEnforcer) Match(resource any) []*Policy {
+	policies, _ := e.MatchContext(context.Background(), resource)
+	return policies
+}
+
+// MatchContext returns the policies that match a given resource, like Match,
+// but stops evaluating as soon as ctx is cancelled or its deadline passes.
+// Policies that cannot be evaluated do not match, as with Match.
+//
+// Parameters:
+// - ctx: The context that bounds the evaluation.
+// - resource: The resource to be evaluated against the policies. The type can be any valid Go type.
+//
+// Returns:
+// - []*Policy: A slice of pointers to policies that match the provided resource.
+// - error: ctx.Err() if evaluation was stopped, in which case no policies are returned.
+func (e PolicyEnforcer) MatchContext(ctx context.Context, resource any) ([]*Policy, error) {
+	policies := *e.Policies
+	c := compiler{operators: e.Operators, strictTypes: e.StrictTypes}
+	ctx = withClock(ctx, e.Clock)
+
+	return matchPolicies(ctx, len(policies), func(i int) (*Policy, PolicyResult) {
+		cp := e.cache.compiledPolicy(c, policies, i)
+		p := policies[i]
+		return &p, cp.evaluate(ctx, resource, false)
+	})
+}
+
+// matchPolicies evaluates count policies in order, using evaluate, and returns
+// those whose rules passed. It returns ctx.Err() if ctx is done part way through.
+func matchPolicies(ctx context.Context, count int, evaluate func(i int) (*Policy, PolicyResult)) ([]*Policy, error) {
 	var policies []*Policy
 
-	for _, p := range *e.Policies {
-		if p.Evaluate(resource) {
-			policies = append(policies, &p) // If the policy matches, append it
+	for i := 0; i < count; i++ {
+		if err := contextError(ctx); err != nil {
+			return nil, err
+		}
+
+		policy, result := evaluate(i)
+
+		// The policy was interrupted rather than failing on its own
+		if result.Err != nil {
+			if err := contextError(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if result.Passed {
+			policies = append(policies, policy) // If the policy matches, append it
 		}
 	}
 
-	return policies
+	return policies, nil
+}
+
+// Decide evaluates a resource against every policy and returns a Decision that
+// explains the outcome.
+//
+// Unlike Enforce, Decide does not stop once the outcome is known. Every
+// rule of every policy, including the rules of condition groups and nested
+// rules, is evaluated so the returned Decision records, for each rule, the
+// field path, the value read from the resource, the operator, the expected
+// value and whether the rule passed. Rules evaluated after a group's result is
+// known do not change it.
+//
+// Parameters:
+// - resource: The resource to be evaluated against the policies. The type can be any valid Go type.
+//
+// Returns:
+//   - Decision: The overall outcome, with Allowed matching the result of Enforce,
+//     and the per-policy and per-rule results.
+func (e PolicyEnforcer) Decide(resource any) Decision {
+	return e.decide(context.Background(), resource, true)
+}
+
+// decide evaluates the policies in order and combines their outcomes with the
+// enforcer's CombiningAlgorithm. When explain is false, evaluation stops as soon
+// as the combined outcome is known and no per-policy results are recorded.
+func (e PolicyEnforcer) decide(ctx context.Context, resource any, explain bool) Decision {
+	if e.Policies == nil {
+		return Decision{Outcome: OutcomeNotApplicable}
+	}
+
+	policies := *e.Policies
+	c := compiler{operators: e.Operators, strictTypes: e.StrictTypes}
+	ctx = withClock(ctx, e.Clock)
+
+	return combinePolicies(ctx, e.Algorithm, len(policies), explain, func(i int) PolicyResult {
+		result := e.cache.compiledPolicy(c, policies, i).evaluate(ctx, resource, explain)
+		result.Policy = &policies[i]
+		return result
+	})
 }
@@ -1,7 +1,12 @@
 package go_policy_enforcer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPolicyEnforcer_Enforce_HappyPath(t *testing.T) {
@@ -854,3 +859,584 @@ func TestPolicyEnforcer_Match_MultipleOperators(t *testing.T) {
 		t.Errorf("Expected %d matched policies, but got %d", expectedMatchedPolicies, len(matchedPolicies))
 	}
 }
+
+func TestPolicyEnforcer_Decide_ExplainsFailingRule(t *testing.T) {
+	// Arrange
+	policies := &[]Policy{
+		{
+			Name: "TestPolicy1",
+			Rules: []Rule{
+				{Field: "Age", Operator: ">", Value: 25},
+			},
+		},
+		{
+			Name: "TestPolicy2",
+			Rules: []Rule{
+				{Field: "Active", Operator: "==", Value: true},
+				{Field: "Status", Operator: "in", Value: []string{"active", "pending"}},
+			},
+		},
+	}
+
+	resource := struct {
+		Age    int
+		Active bool
+		Status string
+	}{
+		Age:    30,
+		Active: false,
+		Status: "active",
+	}
+
+	enforcer := NewPolicyEnforcer(policies)
+
+	// Act
+	decision := enforcer.Decide(resource)
+
+	// Assert
+	if decision.Allowed {
+		t.Fatalf("expected decision to deny, but it allowed")
+	}
+
+	if decision.Allowed != enforcer.Enforce(resource) {
+		t.Errorf("expected Decide and Enforce to agree")
+	}
+
+	if len(decision.Policies) != 2 {
+		t.Fatalf("expected 2 policy results, but got %d", len(decision.Policies))
+	}
+
+	if !decision.Policies[0].Passed || decision.Policies[0].Policy.Name != "TestPolicy1" {
+		t.Errorf("expected TestPolicy1 to pass, got %+v", decision.Policies[0])
+	}
+
+	second := decision.Policies[1]
+	if second.Passed {
+		t.Errorf("expected TestPolicy2 to fail")
+	}
+
+	// Every rule is evaluated, even after the first failure
+	if len(second.Rules) != 2 {
+		t.Fatalf("expected 2 rule results, but got %d", len(second.Rules))
+	}
+
+	failed := second.Rules[0]
+	if failed.Passed || failed.Field != "Active" || failed.Operator != "==" || failed.Value != false || failed.Expected != true {
+		t.Errorf("unexpected rule result for Active: %+v", failed)
+	}
+
+	if !second.Rules[1].Passed {
+		t.Errorf("expected Status rule to pass, got %+v", second.Rules[1])
+	}
+}
+
+func TestPolicyEnforcer_Decide_RecordsErrors(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name: "TestPolicy1",
+			Rules: []Rule{
+				{Field: "Missing", Operator: "==", Value: 1},
+			},
+		},
+	}
+
+	resource := struct{ Age int }{Age: 30}
+
+	decision := NewPolicyEnforcer(policies).Decide(resource)
+
+	if decision.Allowed {
+		t.Fatalf("expected decision to deny, but it allowed")
+	}
+
+	if err := decision.Policies[0].Rules[0].Err; err == nil {
+		t.Errorf("expected an error for a missing field, but got none")
+	}
+}
+
+func TestPolicyEnforcer_Decide_MatchesEnforce(t *testing.T) {
+	type user struct {
+		Role  string
+		Owner string
+	}
+
+	// The rule after the failing one refers to a missing field and errors
+	failing := []Rule{
+		{Field: "Role", Operator: "==", Value: "admin"},
+		{Field: "Missing", Operator: "==", Value: "x"},
+	}
+
+	tests := []struct {
+		name   string
+		admins Policy
+	}{
+		{"rules", Policy{Name: "admins", Effect: EffectAllow, Rules: failing}},
+		{"target", Policy{Name: "admins", Effect: EffectAllow, Target: failing, Rules: []Rule{{Field: "Role", Operator: "==", Value: "admin"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := &[]Policy{
+				tt.admins,
+				{Name: "owners", Effect: EffectAllow, Rules: []Rule{{Field: "Owner", Operator: "==", Value: "bob"}}},
+			}
+
+			enforcer := NewPolicyEnforcer(policies)
+			resource := user{Role: "viewer", Owner: "bob"}
+
+			allowed, err := enforcer.EnforceE(resource)
+			if !allowed || err != nil {
+				t.Fatalf("expected EnforceE to return (true, nil), but got (%v, %v)", allowed, err)
+			}
+
+			decision := enforcer.Decide(resource)
+			if decision.Allowed != allowed || decision.Err != nil || decision.Policies[0].Err != nil {
+				t.Errorf("expected Decide to match Enforce, but got:\n%s", decision)
+			}
+
+			// The later rule's error is still recorded
+			results := decision.Policies[0].Rules
+			if tt.name == "target" {
+				results = decision.Policies[0].Target
+			}
+
+			if len(results) != 2 || results[1].Err == nil {
+				t.Errorf("expected the error of the second rule to be recorded, but got %+v", results)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_Decide_EncodesErrors(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name: "TestPolicy1",
+			Rules: []Rule{
+				{Field: "Missing", Operator: "==", Value: 1},
+			},
+		},
+	}
+
+	decision := NewPolicyEnforcer(policies).Decide(struct{ Age int }{Age: 30})
+
+	encoded, err := json.Marshal(decision)
+	if err != nil {
+		t.Fatalf("unexpected error encoding the decision: %v", err)
+	}
+
+	var decoded Decision
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected error decoding the decision: %v", err)
+	}
+
+	if decoded.Error == "" || decoded.Error != decision.Err.Error() {
+		t.Errorf("expected the decision's error %q, but got %q", decision.Err, decoded.Error)
+	}
+
+	if decoded.Policies[0].Error != decision.Policies[0].Err.Error() {
+		t.Errorf("expected the policy's error %q, but got %q", decision.Policies[0].Err, decoded.Policies[0].Error)
+	}
+
+	if rule := decoded.Policies[0].Rules[0]; !strings.Contains(rule.Error, "Missing") {
+		t.Errorf("expected the rule's error to name the missing field, but got %q", rule.Error)
+	}
+}
+
+func TestPolicyEnforcer_Decide_NestedRules(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name: "TestPolicy1",
+			Rules: []Rule{
+				{
+					Field:    "Items",
+					Operator: "any",
+					Value: []Rule{
+						{Field: "Status", Operator: "==", Value: "active"},
+					},
+				},
+			},
+		},
+	}
+
+	resource := struct {
+		Items []struct{ Status string }
+	}{
+		Items: []struct{ Status string }{
+			{Status: "inactive"},
+			{Status: "active"},
+			{Status: "archived"},
+		},
+	}
+
+	decision := NewPolicyEnforcer(policies).Decide(resource)
+
+	if !decision.Allowed {
+		t.Fatalf("expected decision to allow, but it denied:\n%s", decision)
+	}
+
+	// Every element is recorded, including those after the first match
+	nested := decision.Policies[0].Rules[0].Rules
+	if len(nested) != 3 {
+		t.Fatalf("expected 3 nested rule results, but got %d", len(nested))
+	}
+
+	if nested[1].Field != "Items[1].Status" || !nested[1].Passed {
+		t.Errorf("unexpected nested rule result: %+v", nested[1])
+	}
+}
+
+func TestPolicyEnforcer_Decide_EmptyPolicies(t *testing.T) {
+	decision := NewPolicyEnforcer(&[]Policy{}).Decide(struct{}{})
+
+	if decision.Allowed {
+		t.Errorf("expected decision to deny when there are no policies, but it allowed")
+	}
+}
+
+func TestPolicyEnforcer_EnforceE(t *testing.T) {
+	resource := struct {
+		Age    int
+		Active bool
+	}{
+		Age:    30,
+		Active: true,
+	}
+
+	tests := []struct {
+		name        string
+		policies    []Policy
+		expected    bool
+		expectedErr error
+	}{
+		{
+			name: "allowed",
+			policies: []Policy{
+				{Name: "TestPolicy1", Rules: []Rule{{Field: "Age", Operator: "==", Value: 30}}},
+			},
+			expected: true,
+		},
+		{
+			name: "denied",
+			policies: []Policy{
+				{Name: "TestPolicy1", Rules: []Rule{{Field: "Age", Operator: "==", Value: 31}}},
+			},
+			expected: false,
+		},
+		{
+			name: "broken",
+			policies: []Policy{
+				{Name: "TestPolicy1", Rules: []Rule{{Field: "Age", Operator: "==", Value: 30}}},
+				{Name: "TestPolicy2", Rules: []Rule{{Field: "Missing", Operator: "==", Value: true}}},
+			},
+			expected:    false,
+			expectedErr: ErrFieldNotFound,
+		},
+		{
+			name:     "no policies",
+			policies: []Policy{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforcer := NewPolicyEnforcer(&tt.policies)
+
+			result, err := enforcer.EnforceE(resource)
+			if result != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, result)
+			}
+
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected no error, but got %v", err)
+			}
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error wrapping %v, but got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_WithCombiningAlgorithm(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name: "IsOwner",
+			Rules: []Rule{
+				{Field: "OwnerID", Operator: "==", Value: 7},
+			},
+		},
+		{
+			Name: "IsAdmin",
+			Rules: []Rule{
+				{Field: "Role", Operator: "==", Value: "admin"},
+			},
+		},
+	}
+
+	resource := struct {
+		OwnerID int
+		Role    string
+	}{
+		OwnerID: 1,
+		Role:    "admin",
+	}
+
+	tests := []struct {
+		algorithm CombiningAlgorithm
+		expected  bool
+	}{
+		{DenyOverrides, false},
+		{PermitOverrides, true},
+		{FirstApplicable, false},
+		{OnlyOneApplicable, false},
+		{Majority, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			enforcer := NewPolicyEnforcer(policies, WithCombiningAlgorithm(tt.algorithm))
+
+			if result := enforcer.Enforce(resource); result != tt.expected {
+				t.Errorf("expected Enforce to return %v, but got %v", tt.expected, result)
+			}
+
+			decision := enforcer.Decide(resource)
+			if decision.Allowed != tt.expected {
+				t.Errorf("expected Decide to return %v, but got %v", tt.expected, decision.Allowed)
+			}
+
+			if len(decision.Policies) != 2 {
+				t.Errorf("expected Decide to evaluate both policies, but got %d", len(decision.Policies))
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_EnforceE_PolicyConflict(t *testing.T) {
+	policies := &[]Policy{
+		{Name: "TestPolicy1", Rules: []Rule{{Field: "Age", Operator: ">", Value: 18}}},
+		{Name: "TestPolicy2", Rules: []Rule{{Field: "Age", Operator: "<", Value: 65}}},
+	}
+
+	enforcer := NewPolicyEnforcer(policies, WithCombiningAlgorithm(OnlyOneApplicable))
+
+	ok, err := enforcer.EnforceE(struct{ Age int }{Age: 30})
+	if ok {
+		t.Errorf("expected EnforceE to return false, but got true")
+	}
+
+	if !errors.Is(err, ErrPolicyConflict) {
+		t.Errorf("expected ErrPolicyConflict, but got %v", err)
+	}
+}
+
+func TestPolicyEnforcer_Enforce_EffectsAndTargets(t *testing.T) {
+	policies := &[]Policy{
+		{
+			Name:   "DenyArchived",
+			Effect: EffectDeny,
+			Rules: []Rule{
+				{Field: "Archived", Operator: "==", Value: true},
+			},
+		},
+		{
+			Name:   "AllowOwner",
+			Effect: EffectAllow,
+			Target: []Rule{
+				{Field: "Kind", Operator: "==", Value: "document"},
+			},
+			Rules: []Rule{
+				{Field: "OwnerID", Operator: "==", Value: 7},
+			},
+		},
+	}
+
+	type Document struct {
+		Kind     string
+		OwnerID  int
+		Archived bool
+	}
+
+	tests := []struct {
+		name     string
+		resource Document
+		outcome  Outcome
+	}{
+		{"owner", Document{Kind: "document", OwnerID: 7}, OutcomePermit},
+		{"archived owner", Document{Kind: "document", OwnerID: 7, Archived: true}, OutcomeDeny},
+		{"stranger", Document{Kind: "document", OwnerID: 1}, OutcomeNotApplicable},
+		{"not a document", Document{Kind: "folder", OwnerID: 7}, OutcomeNotApplicable},
+	}
+
+	enforcer := NewPolicyEnforcer(policies)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := enforcer.Decide(tt.resource)
+
+			if decision.Outcome != tt.outcome {
+				t.Errorf("expected outcome %s, but got %s", tt.outcome, decision.Outcome)
+			}
+
+			expected := tt.outcome == OutcomePermit
+			if result := enforcer.Enforce(tt.resource); result != expected {
+				t.Errorf("expected Enforce to return %v, but got %v", expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_EnforceContext(t *testing.T) {
+	policies := &[]Policy{
+		{Name: "Adults", Rules: []Rule{{Field: "Age", Operator: ">=", Value: 18}}},
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		age      int
+		expected bool
+		err      error
+	}{
+		{"allowed", context.Background(), 30, true, nil},
+		{"denied", context.Background(), 12, false, nil},
+		{"cancelled", cancelled, 30, false, context.Canceled},
+		{"deadline exceeded", expired, 30, false, context.DeadlineExceeded},
+	}
+
+	enforcer := NewPolicyEnforcer(policies)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := enforcer.EnforceContext(tt.ctx, struct{ Age int }{Age: tt.age})
+			if ok != tt.expected {
+				t.Errorf("expected EnforceContext to return %v, but got %v", tt.expected, ok)
+			}
+
+			// The context error is returned as is, not wrapped in a *RuleError
+			if err != tt.err {
+				t.Errorf("expected error %v, but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_EnforceContext_StopsBetweenElements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	registry := NewOperatorRegistry()
+	_ = registry.RegisterContext("cancel_after_two", func(ctx context.Context, leftVal, rightVal any) bool {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return false
+	})
+
+	type Item struct{ Name string }
+
+	policies := &[]Policy{
+		{
+			Name: "NoMatches",
+			Rules: []Rule{
+				{Field: "Items", Operator: "any", Value: []Rule{
+					{Field: "Name", Operator: "cancel_after_two", Value: ""},
+				}},
+			},
+		},
+		{Name: "Never", Rules: []Rule{{Field: "Items[0].Name", Operator: "cancel_after_two", Value: ""}}},
+	}
+
+	resource := struct{ Items []Item }{Items: make([]Item, 100)}
+
+	enforcer := NewPolicyEnforcer(policies, WithCombiningAlgorithm(PermitOverrides), WithOperatorRegistry(registry))
+
+	ok, err := enforcer.EnforceContext(ctx, resource)
+	if ok || err != context.Canceled {
+		t.Errorf("expected (false, context.Canceled), but got (%v, %v)", ok, err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected evaluation to stop after 2 elements, but the operator was called %d times", calls)
+	}
+}
+
+func TestPolicyEnforcer_EnforceContext_PassesValuesToOperators(t *testing.T) {
+	type callerKey struct{}
+
+	registry := NewOperatorRegistry()
+	_ = registry.RegisterContext("is_caller", func(ctx context.Context, leftVal, rightVal any) bool {
+		caller, ok := ctx.Value(callerKey{}).(string)
+		return ok && caller == leftVal
+	})
+
+	policies := &[]Policy{
+		{Name: "Owner", Rules: []Rule{{Field: "Owner", Operator: "is_caller", Value: nil}}},
+	}
+
+	enforcer := NewPolicyEnforcer(policies, WithOperatorRegistry(registry))
+	resource := struct{ Owner string }{Owner: "alice"}
+
+	tests := []struct {
+		caller   string
+		expected bool
+	}{
+		{"alice", true},
+		{"bob", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.caller, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), callerKey{}, tt.caller)
+
+			ok, err := enforcer.EnforceContext(ctx, resource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ok != tt.expected {
+				t.Errorf("expected EnforceContext to return %v, but got %v", tt.expected, ok)
+			}
+		})
+	}
+
+	if enforcer.Enforce(resource) {
+		t.Errorf("expected Enforce without a caller to return false, but got true")
+	}
+}
+
+func TestPolicyEnforcer_MatchContext(t *testing.T) {
+	policies := &[]Policy{
+		{Name: "Adults", Rules: []Rule{{Field: "Age", Operator: ">=", Value: 18}}},
+		{Name: "Seniors", Rules: []Rule{{Field: "Age", Operator: ">=", Value: 65}}},
+		{Name: "Broken", Rules: []Rule{{Field: "Missing", Operator: "==", Value: 1}}},
+	}
+
+	enforcer := NewPolicyEnforcer(policies)
+	resource := struct{ Age int }{Age: 30}
+
+	matches, err := enforcer.MatchContext(context.Background(), resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches) != 1 || matches[0].Name != "Adults" {
+		t.Errorf("expected only Adults to match, but got %v", matches)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	matches, err = enforcer.MatchContext(ctx, resource)
+	if matches != nil || err != context.Canceled {
+		t.Errorf("expected (nil, context.Canceled), but got (%v, %v)", matches, err)
+	}
+}
@@ -1,9 +1,14 @@
 package go_policy_enforcer
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math/big"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -115,6 +120,53 @@ func TestLoadPolicy_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestLoadPolicy_DecimalValues(t *testing.T) {
+	type payment struct {
+		Amount *big.Rat
+		Quoted string
+	}
+
+	policyFile := filepath.Join(t.TempDir(), "money.json")
+	data := `{"name": "Money", "rules": [
+		{"field": "Amount", "operator": "==", "value": 19.99},
+		{"field": "Quoted", "operator": "<", "value": 12345678901234567.02}
+	]}`
+
+	if err := os.WriteFile(policyFile, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to create policy file: %v", err)
+	}
+
+	policy, err := LoadPolicy(policyFile)
+	if err != nil {
+		t.Fatalf("unexpected error loading policy: %v", err)
+	}
+
+	if value := policy.Rules[1].Value; value != json.Number("12345678901234567.02") {
+		t.Errorf("expected the value to be decoded as a json.Number, got %v (%T)", value, value)
+	}
+
+	input := payment{Amount: big.NewRat(1999, 100), Quoted: "12345678901234567.01"}
+	if !policy.Evaluate(input) {
+		t.Error("expected the policy to pass")
+	}
+
+	input.Quoted = "12345678901234567.02"
+	if policy.Evaluate(input) {
+		t.Error("expected the policy to fail, cents past float precision are compared")
+	}
+}
+
+func TestLoadPolicy_TrailingData(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(policyFile, []byte(`{"name": "A"} {"name": "B"}`), 0o644); err != nil {
+		t.Fatalf("failed to create policy file: %v", err)
+	}
+
+	if _, err := LoadPolicy(policyFile); err == nil {
+		t.Error("expected error when loading a file with data after the policy, but got none")
+	}
+}
+
 func TestPolicy_Match_NestedStructs(t *testing.T) {
 	nestedPolicy := Policy{
 		Name: "NestedPolicy",
@@ -568,3 +620,434 @@ func TestPolicy_Evaluate_NonExistentField(t *testing.T) {
 		t.Error("Policy evaluation should fail for non-existent field in nested struct")
 	}
 }
+
+func TestPolicy_EvaluateE_Errors(t *testing.T) {
+	type InnerStruct struct {
+		innerField string
+	}
+
+	type TestStruct struct {
+		Name   string
+		Items  []string
+		Inner  InnerStruct
+		Labels map[string]string
+	}
+
+	resource := TestStruct{
+		Name:   "name",
+		Items:  []string{"a"},
+		Inner:  InnerStruct{innerField: "hidden"},
+		Labels: map[string]string{"team": "core"},
+	}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected error
+	}{
+		{"missing field", Rule{Field: "Missing", Operator: "==", Value: 1}, ErrFieldNotFound},
+		{"missing map key", Rule{Field: "Labels.owner", Operator: "==", Value: "x"}, ErrFieldNotFound},
+		{"path through non struct", Rule{Field: "Name.Length", Operator: "==", Value: 1}, ErrFieldNotFound},
+		{"unexported field", Rule{Field: "Inner.innerField", Operator: "==", Value: "x"}, ErrUnexportedField},
+		{"unknown operator", Rule{Field: "Name", Operator: "~=", Value: "x"}, ErrOperatorNotSupported},
+		{"index out of range", Rule{Field: "Items[5]", Operator: "==", Value: "a"}, ErrIndexOutOfRange},
+		{"negative index", Rule{Field: "Items[-1]", Operator: "==", Value: "a"}, ErrIndexOutOfRange},
+		{"index into non slice", Rule{Field: "Name[0]", Operator: "==", Value: "a"}, ErrTypeMismatch},
+		{"slice operator mismatch", Rule{Field: "Items", Operator: ">", Value: []string{"a"}}, ErrOperatorNotSupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "ErrorPolicy", Rules: []Rule{tt.rule}}
+
+			ok, err := policy.EvaluateE(resource)
+			if ok {
+				t.Errorf("expected EvaluateE to return false, but got true")
+			}
+
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected error wrapping %v, but got %v", tt.expected, err)
+			}
+
+			var ruleErr *RuleError
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("expected a *RuleError, but got %T", err)
+			}
+
+			if ruleErr.Policy != "ErrorPolicy" || ruleErr.Rule.Field != tt.rule.Field {
+				t.Errorf("unexpected RuleError: %+v", ruleErr)
+			}
+
+			// Evaluate keeps treating errors as a non-match
+			if policy.Evaluate(resource) {
+				t.Errorf("expected Evaluate to return false, but got true")
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_DenyIsNotAnError(t *testing.T) {
+	policy := Policy{
+		Name: "TestPolicy",
+		Rules: []Rule{
+			{Field: "Age", Operator: ">", Value: 30},
+		},
+	}
+
+	ok, err := policy.EvaluateE(struct{ Age int }{Age: 20})
+	if ok || err != nil {
+		t.Errorf("expected (false, nil), but got (%v, %v)", ok, err)
+	}
+
+	ok, err = policy.EvaluateE(struct{ Age int }{Age: 40})
+	if !ok || err != nil {
+		t.Errorf("expected (true, nil), but got (%v, %v)", ok, err)
+	}
+}
+
+func TestPolicy_EvaluateE_NonStructResource(t *testing.T) {
+	policy := Policy{Name: "TestPolicy"}
+
+	_, err := policy.EvaluateE("not a struct")
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, but got %v", err)
+	}
+}
+
+func TestPolicy_EvaluateE_NestedRuleError(t *testing.T) {
+	policy := Policy{
+		Name: "TestPolicy",
+		Rules: []Rule{
+			{
+				Field:    "Items",
+				Operator: "any",
+				Value: []Rule{
+					{Field: "Missing", Operator: "==", Value: "active"},
+				},
+			},
+		},
+	}
+
+	resource := struct {
+		Items []struct{ Status string }
+	}{
+		Items: []struct{ Status string }{{Status: "active"}},
+	}
+
+	_, err := policy.EvaluateE(resource)
+	if !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("expected ErrFieldNotFound from nested rule, but got %v", err)
+	}
+}
+
+func TestPolicy_Evaluate_ConditionGroups(t *testing.T) {
+	type User struct {
+		ID         int
+		IsLoggedIn bool
+		Roles      []string
+	}
+
+	type Document struct {
+		OwnerID int
+		User    User
+	}
+
+	// owner OR (admin AND logged in)
+	policy := Policy{
+		Name: "CanEditDocument",
+		Rules: []Rule{
+			{
+				Any: []Rule{
+					{Field: "User.ID", Operator: "==", Value: 7},
+					{
+						All: []Rule{
+							{Field: "User.Roles", Operator: "in", Value: "admin"},
+							{Field: "User.IsLoggedIn", Operator: "==", Value: true},
+						},
+					},
+				},
+			},
+			{
+				Not: &Rule{Field: "OwnerID", Operator: "==", Value: 0},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		resource Document
+		expected bool
+	}{
+		{"owner", Document{OwnerID: 7, User: User{ID: 7}}, true},
+		{"logged in admin", Document{OwnerID: 7, User: User{ID: 1, IsLoggedIn: true, Roles: []string{"admin"}}}, true},
+		{"logged out admin", Document{OwnerID: 7, User: User{ID: 1, Roles: []string{"admin"}}}, false},
+		{"stranger", Document{OwnerID: 7, User: User{ID: 1, IsLoggedIn: true}}, false},
+		{"unowned document", Document{OwnerID: 0, User: User{ID: 7}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := policy.Evaluate(tt.resource); result != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate_ConditionGroups_ShortCircuit(t *testing.T) {
+	resource := struct{ Age int }{Age: 30}
+
+	// The second rule of each group refers to a missing field and would error if evaluated
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected bool
+	}{
+		{"any stops at first pass", Rule{Any: []Rule{{Field: "Age", Operator: "==", Value: 30}, {Field: "Missing", Operator: "==", Value: 1}}}, true},
+		{"all stops at first fail", Rule{All: []Rule{{Field: "Age", Operator: "==", Value: 31}, {Field: "Missing", Operator: "==", Value: 1}}}, false},
+		{"empty all passes", Rule{All: []Rule{}}, true},
+		{"empty any fails", Rule{Any: []Rule{}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "TestPolicy", Rules: []Rule{tt.rule}}
+
+			result, err := policy.EvaluateE(resource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, result)
+			}
+
+			// Decide records every rule of the group without changing its result
+			decision := NewPolicyEnforcer(&[]Policy{policy}).Decide(resource)
+			group := decision.Policies[0].Rules[0]
+
+			if decision.Allowed != tt.expected || group.Passed != tt.expected || group.Err != nil {
+				t.Errorf("expected Decide to report %v without an error, but got:\n%s", tt.expected, decision)
+			}
+
+			if len(group.Rules) != len(tt.rule.All)+len(tt.rule.Any) {
+				t.Errorf("expected Decide to record %d rules, but got %d", len(tt.rule.All)+len(tt.rule.Any), len(group.Rules))
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidConditionGroups(t *testing.T) {
+	resource := struct{ Age int }{Age: 30}
+	ageRule := Rule{Field: "Age", Operator: "==", Value: 30}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected error
+		message  string
+	}{
+		{"multiple combinators", Rule{All: []Rule{ageRule}, Any: []Rule{ageRule}}, ErrInvalidRule, `policy "TestPolicy": rule 'all': `},
+		{"group with field", Rule{Field: "Age", All: []Rule{ageRule}}, ErrInvalidRule, `policy "TestPolicy": rule 'all': `},
+		{"error inside group", Rule{Not: &Rule{Field: "Missing", Operator: "==", Value: 1}}, ErrFieldNotFound, `policy "TestPolicy": rule 'not': `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "TestPolicy", Rules: []Rule{tt.rule}}
+
+			_, err := policy.EvaluateE(resource)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected error wrapping %v, but got %v", tt.expected, err)
+			}
+
+			// Groups are described by their kind rather than an empty comparison
+			if !strings.HasPrefix(err.Error(), tt.message) {
+				t.Errorf("expected the error to start with %q, but got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestRule_MarshalJSON_ConditionGroups(t *testing.T) {
+	rule := Rule{Any: []Rule{
+		{Field: "IsOwner", Operator: "==", Value: true},
+		{Not: &Rule{Field: "Locked", Operator: "==", Value: false}},
+	}}
+
+	encoded, err := json.Marshal(rule)
+	if err != nil {
+		t.Fatalf("unexpected error encoding the rule: %v", err)
+	}
+
+	expected := `{"any":[{"field":"IsOwner","operator":"==","value":true},{"not":{"field":"Locked","operator":"==","value":false}}]}`
+	if string(encoded) != expected {
+		t.Errorf("expected %s, but got %s", expected, encoded)
+	}
+}
+
+func TestLoadPolicy_ConditionGroups(t *testing.T) {
+	policyJSON := `{
+  "name": "CanEditDocument",
+  "rules": [
+    {
+      "any": [
+        {"field": "IsOwner", "operator": "==", "value": true},
+        {
+          "all": [
+            {"field": "IsAdmin", "operator": "==", "value": true},
+            {"not": {"field": "IsLoggedIn", "operator": "==", "value": false}}
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+	policyFile := "group_policy.json"
+	if err := os.WriteFile(policyFile, []byte(policyJSON), 0o644); err != nil {
+		t.Fatalf("failed to create policy file: %v", err)
+	}
+
+	defer func(name string) {
+		if err := os.Remove(name); err != nil {
+			t.Errorf("failed to remove policy file: %v", err)
+		}
+	}(policyFile)
+
+	policy, err := LoadPolicy(policyFile)
+	if err != nil {
+		t.Fatalf("unexpected error loading policy: %v", err)
+	}
+
+	type User struct {
+		IsOwner    bool
+		IsAdmin    bool
+		IsLoggedIn bool
+	}
+
+	if !policy.Evaluate(User{IsAdmin: true, IsLoggedIn: true}) {
+		t.Errorf("expected logged in admin to pass")
+	}
+
+	if policy.Evaluate(User{IsAdmin: true}) {
+		t.Errorf("expected logged out admin to fail")
+	}
+
+	if !policy.Evaluate(User{IsOwner: true}) {
+		t.Errorf("expected owner to pass")
+	}
+}
+
+func TestPolicy_Evaluate_EffectAndTarget(t *testing.T) {
+	type Document struct {
+		Kind     string
+		OwnerID  int
+		Archived bool
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		resource Document
+		passed   bool
+		outcome  Outcome
+	}{
+		{
+			name:     "constraint passes",
+			policy:   Policy{Rules: []Rule{{Field: "OwnerID", Operator: "==", Value: 7}}},
+			resource: Document{OwnerID: 7},
+			passed:   true,
+			outcome:  OutcomePermit,
+		},
+		{
+			name:     "constraint fails",
+			policy:   Policy{Rules: []Rule{{Field: "OwnerID", Operator: "==", Value: 7}}},
+			resource: Document{OwnerID: 1},
+			passed:   false,
+			outcome:  OutcomeDeny,
+		},
+		{
+			name:     "allow matches",
+			policy:   Policy{Effect: EffectAllow, Rules: []Rule{{Field: "OwnerID", Operator: "==", Value: 7}}},
+			resource: Document{OwnerID: 7},
+			passed:   true,
+			outcome:  OutcomePermit,
+		},
+		{
+			name:     "allow does not match",
+			policy:   Policy{Effect: EffectAllow, Rules: []Rule{{Field: "OwnerID", Operator: "==", Value: 7}}},
+			resource: Document{OwnerID: 1},
+			passed:   false,
+			outcome:  OutcomeNotApplicable,
+		},
+		{
+			name:     "deny matches",
+			policy:   Policy{Effect: EffectDeny, Rules: []Rule{{Field: "Archived", Operator: "==", Value: true}}},
+			resource: Document{Archived: true},
+			passed:   true,
+			outcome:  OutcomeDeny,
+		},
+		{
+			name:     "deny does not match",
+			policy:   Policy{Effect: EffectDeny, Rules: []Rule{{Field: "Archived", Operator: "==", Value: true}}},
+			resource: Document{Archived: false},
+			passed:   false,
+			outcome:  OutcomeNotApplicable,
+		},
+		{
+			name: "target does not match",
+			policy: Policy{
+				Target: []Rule{{Field: "Kind", Operator: "==", Value: "document"}},
+				Rules:  []Rule{{Field: "OwnerID", Operator: "==", Value: 7}},
+			},
+			resource: Document{Kind: "folder", OwnerID: 7},
+			passed:   false,
+			outcome:  OutcomeNotApplicable,
+		},
+		{
+			name: "target matches",
+			policy: Policy{
+				Target: []Rule{{Field: "Kind", Operator: "==", Value: "document"}},
+				Rules:  []Rule{{Field: "OwnerID", Operator: "==", Value: 7}},
+			},
+			resource: Document{Kind: "document", OwnerID: 1},
+			passed:   false,
+			outcome:  OutcomeDeny,
+		},
+		{
+			name: "target error",
+			policy: Policy{
+				Target: []Rule{{Field: "Missing", Operator: "==", Value: "document"}},
+			},
+			resource: Document{},
+			passed:   false,
+			outcome:  OutcomeIndeterminate,
+		},
+		{
+			name:     "unknown effect",
+			policy:   Policy{Effect: "audit"},
+			resource: Document{},
+			passed:   false,
+			outcome:  OutcomeIndeterminate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.policy.evaluate(context.Background(), tt.resource, true)
+
+			if result.Passed != tt.passed {
+				t.Errorf("expected passed to be %v, but got %v", tt.passed, result.Passed)
+			}
+
+			if result.Outcome != tt.outcome {
+				t.Errorf("expected outcome %s, but got %s", tt.outcome, result.Outcome)
+			}
+
+			if tt.policy.Evaluate(tt.resource) != tt.passed {
+				t.Errorf("expected Evaluate to agree with the policy result")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package go_policy_enforcer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Range is the value of a "between" rule. Both bounds are included unless
+// excluded, so Range{Min: 18, Max: 65} passes for 18 through 65.
+//
+// In JSON a Range is written as a two element list, [18, 65], which includes
+// both bounds, or as an object such as {"min": 18, "max": 65, "excludeMax": true}.
+//
+// The Range struct has the following fields:
+// - Min: The lower bound.
+// - Max: The upper bound.
+// - ExcludeMin: Whether a value equal to Min fails.
+// - ExcludeMax: Whether a value equal to Max fails.
+type Range struct {
+	Min        any  `json:"min"`
+	Max        any  `json:"max"`
+	ExcludeMin bool `json:"excludeMin,omitempty"`
+	ExcludeMax bool `json:"excludeMax,omitempty"`
+}
+
+// String returns the range in interval notation, e.g. "[18, 65)".
+func (r Range) String() string {
+	open, end := "[", "]"
+
+	if r.ExcludeMin {
+		open = "("
+	}
+
+	if r.ExcludeMax {
+		end = ")"
+	}
+
+	return fmt.Sprintf("%s%v, %v%s", open, r.Min, r.Max, end)
+}
+
+// contains reports whether v lies within the range, ordered by compare. It is
+// false when v cannot be ordered against the bounds.
+func (r Range) contains(v any, compare func(leftVal, rightVal any) (int, bool)) bool {
+	c, ok := compare(v, r.Min)
+	if !ok || c < 0 || (c == 0 && r.ExcludeMin) {
+		return false
+	}
+
+	c, ok = compare(v, r.Max)
+
+	return ok && (c < 0 || (c == 0 && !r.ExcludeMax))
+}
+
+// asRange converts the value of a "between" rule to a Range. The value can be a
+// Range, a *Range, a two element list or the decoded JSON object form.
+func asRange(value any) (Range, error) {
+	switch v := value.(type) {
+	case Range:
+		return v, nil
+	case *Range:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]any:
+		return rangeFromMap(v)
+	default:
+		rv := reflect.ValueOf(value)
+		if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Len() == 2 {
+			return Range{Min: rv.Index(0).Interface(), Max: rv.Index(1).Interface()}, nil
+		}
+	}
+
+	return Range{}, newEvaluationError(ErrInvalidRule, "the value of a between rule must be a range or a two element list, got %T", value)
+}
+
+// rangeFromMap converts the JSON object form of a Range.
+func rangeFromMap(m map[string]any) (Range, error) {
+	var r Range
+
+	for key, value := range m {
+		var ok bool
+
+		switch key {
+		case "min":
+			r.Min, ok = value, true
+		case "max":
+			r.Max, ok = value, true
+		case "excludeMin":
+			r.ExcludeMin, ok = value.(bool)
+		case "excludeMax":
+			r.ExcludeMax, ok = value.(bool)
+		default:
+			return Range{}, newEvaluationError(ErrInvalidRule, "unknown range key %q", key)
+		}
+
+		if !ok {
+			return Range{}, newEvaluationError(ErrInvalidRule, "range key %q must be a boolean, got %T", key, value)
+		}
+	}
+
+	return r, nil
+}
+
+// prepareRange validates the value of a "between" rule: both bounds must be
+// present, comparable with each other and in order.
+func prepareRange(value any) (any, error) {
+	r, err := asRange(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Min == nil || r.Max == nil {
+		return nil, newEvaluationError(ErrInvalidRule, "range %v must have both bounds", r)
+	}
+
+	c, ok := compareOrdered(r.Min, r.Max)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "range bounds %v (%T) and %v (%T) cannot be compared", r.Min, r.Min, r.Max, r.Max)
+	}
+
+	if c > 0 {
+		return nil, newEvaluationError(ErrInvalidRule, "range %v has a lower bound greater than its upper bound", r)
+	}
+
+	return r, nil
+}
+
+// betweenPolicyCheckOperator checks if the left value lies within the Range in
+// the right value. It works for numbers, strings, times and durations, ordered
+// as described by compareOrdered, as the comparison operators order them, and
+// returns false for values that cannot be ordered against the bounds. NaN is in
+// no range, and +Inf and -Inf are only in ranges with an infinite bound of the
+// same sign, which cannot be written in JSON policies. Bounds that are NaN are
+// an ErrInvalidRule error.
+var betweenPolicyCheckOperator = rangePolicyCheckOperator(compareOrdered)
+
+// strictBetweenPolicyCheckOperator is "between" with strict types, which orders
+// values with compareOrderedStrictly.
+var strictBetweenPolicyCheckOperator = rangePolicyCheckOperator(compareOrderedStrictly)
+
+// rangeKinds returns the kinds of the bounds of the Range in value, which a
+// field must be compatible with to be checked with "between" with strict types.
+// A value that is not a valid range has no kinds, as it is reported when the
+// rule is evaluated.
+func rangeKinds(value any) []string {
+	r, ok := value.(Range)
+	if !ok {
+		prepared, err := prepareRange(value)
+		if err != nil {
+			return nil
+		}
+
+		r = prepared.(Range)
+	}
+
+	return append(operandKinds(r.Min), operandKinds(r.Max)...)
+}
+
+// rangePolicyCheckOperator returns an operator that checks if the left value
+// lies within the Range in the right value, ordered by compare.
+func rangePolicyCheckOperator(compare func(leftVal, rightVal any) (int, bool)) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		r, ok := rightVal.(Range)
+		if !ok {
+			prepared, err := prepareRange(rightVal)
+			if err != nil {
+				return false
+			}
+
+			r = prepared.(Range)
+		}
+
+		return r.contains(leftVal, compare)
+	}
+}
@@ -0,0 +1,184 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBetweenPolicyCheckOperator(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{30, []int{18, 65}, true},
+		{18, []int{18, 65}, true},
+		{65, []any{18, 65}, true},
+		{17, []int{18, 65}, false},
+		{18, Range{Min: 18, Max: 65, ExcludeMin: true}, false},
+		{65, &Range{Min: 18, Max: 65, ExcludeMax: true}, false},
+		{64.5, Range{Min: 18, Max: 65, ExcludeMax: true}, true},
+		{"30", []any{18.0, 65.0}, true},
+		{"m", []string{"a", "n"}, true},
+		{"z", []string{"a", "n"}, false},
+		{45 * time.Minute, []string{"30m", "1h"}, true},
+		{2 * time.Hour, []time.Duration{30 * time.Minute, time.Hour}, false},
+		{start.AddDate(0, 6, 0), []time.Time{start, end}, true},
+		{end, Range{Min: start, Max: end, ExcludeMax: true}, false},
+		{"2024-06-01T00:00:00Z", []time.Time{start, end}, true},
+		{map[string]any{}, []int{18, 65}, false},
+		{[]int{30}, []int{18, 65}, false},
+		{30, 18, false},
+		{math.NaN(), []float64{math.Inf(-1), math.Inf(1)}, false},
+		{math.Inf(1), []any{0, 1e308}, false},
+		{math.Inf(1), []float64{0, math.Inf(1)}, true},
+		{math.Inf(1), Range{Min: 0, Max: math.Inf(1), ExcludeMax: true}, false},
+		{math.Inf(-1), []float64{math.Inf(-1), 0}, true},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator("between", tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v between %v: %v", tt.leftVal, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v between %v = %v; want %v", tt.leftVal, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestRange_String(t *testing.T) {
+	tests := []struct {
+		r        Range
+		expected string
+	}{
+		{Range{Min: 18, Max: 65}, "[18, 65]"},
+		{Range{Min: 18, Max: 65, ExcludeMin: true}, "(18, 65]"},
+		{Range{Min: "a", Max: "n", ExcludeMax: true}, "[a, n)"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.r.String(); result != tt.expected {
+			t.Errorf("expected %q, but got %q", tt.expected, result)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Between(t *testing.T) {
+	type Employee struct {
+		Age    int
+		Salary float64
+	}
+
+	policies := []Policy{
+		{
+			Name: "WorkingAge",
+			Rules: []Rule{
+				{Field: "Age", Operator: "between", Value: []any{18, 65}},
+				{Field: "Salary", Operator: "between", Value: map[string]any{"min": 0, "max": 100000, "excludeMin": true}},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Employee{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		employee Employee
+		expected bool
+	}{
+		{"in range", Employee{Age: 30, Salary: 50000}, true},
+		{"on both inclusive bounds", Employee{Age: 65, Salary: 100000}, true},
+		{"too young", Employee{Age: 16, Salary: 50000}, false},
+		{"on an exclusive bound", Employee{Age: 30, Salary: 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := policies[0].Evaluate(tt.employee); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(tt.employee); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_Explain_Between(t *testing.T) {
+	policy := Policy{
+		Name:  "WorkingAge",
+		Rules: []Rule{{Field: "Age", Operator: "between", Value: Range{Min: 18, Max: 65, ExcludeMax: true}}},
+	}
+
+	decision := NewPolicyEnforcer(&[]Policy{policy}).Decide(struct{ Age int }{Age: 70})
+
+	if len(decision.Policies) != 1 || len(decision.Policies[0].Rules) != 1 {
+		t.Fatalf("expected a single rule result, but got %+v", decision.Policies)
+	}
+
+	expected := "Age between [18, 65): fail (got 70)"
+	if !strings.Contains(decision.String(), expected) {
+		t.Errorf("expected the explanation to contain %q, but got:\n%s", expected, decision.String())
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"single bound", []int{18}},
+		{"three bounds", []int{18, 65, 70}},
+		{"not a list", 18},
+		{"missing bound", map[string]any{"min": 18}},
+		{"unknown key", map[string]any{"min": 18, "max": 65, "inclusive": true}},
+		{"non boolean flag", map[string]any{"min": 18, "max": 65, "excludeMin": "yes"}},
+		{"reversed bounds", []int{65, 18}},
+		{"incomparable bounds", []any{18, time.Hour}},
+		{"NaN bound", []float64{math.NaN(), 65}},
+		{"nil range", (*Range)(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Range", Rules: []Rule{{Field: "Age", Operator: "between", Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ Age int }{Age: 30})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ Age int }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy_InvalidRange(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{"Name": "Range", "Rules": [{"field": "Age", "operator": "between", "value": {"min": 65, "max": 18}}]}`
+
+	if err := os.WriteFile(policyFile, []byte(policy), 0o644); err != nil {
+		t.Fatalf("failed to create policy file: %v", err)
+	}
+
+	if _, err := LoadPolicy(policyFile); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule, but got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package go_policy_enforcer
+
+import (
+	"regexp"
+	"sync"
+)
+
+// maxCachedPatterns bounds the number of compiled patterns kept by
+// compilePattern, so patterns read from resources cannot grow it forever.
+const maxCachedPatterns = 1024
+
+// patternCache holds compiled regular expressions by pattern, so a pattern is
+// compiled once however many times a rule using it is evaluated.
+var patternCache = struct {
+	sync.Mutex
+	patterns map[string]*regexp.Regexp
+}{patterns: map[string]*regexp.Regexp{}}
+
+// pattern is a rule value that has been compiled into a regular expression. It
+// is a struct, rather than a *regexp.Regexp, so that it is not dereferenced on
+// its way to the operator.
+type pattern struct {
+	re *regexp.Regexp
+}
+
+// compilePattern compiles an RE2 pattern, reusing the result of an earlier call
+// with the same pattern.
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	patternCache.Lock()
+	defer patternCache.Unlock()
+
+	if re, ok := patternCache.patterns[expr]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patternCache.patterns) < maxCachedPatterns {
+		patternCache.patterns[expr] = re
+	}
+
+	return re, nil
+}
+
+// preparePattern validates the value of a "matches" or "not matches" rule and
+// compiles it, so invalid patterns are reported before evaluation.
+func preparePattern(value any) (any, error) {
+	expr, ok := value.(string)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a pattern rule must be a string, got %T", value)
+	}
+
+	re, err := compilePattern(expr)
+	if err != nil {
+		return nil, newEvaluationError(ErrInvalidRule, "invalid pattern %q: %v", expr, err)
+	}
+
+	return pattern{re: re}, nil
+}
+
+// matchPattern reports whether leftVal, a string or a slice of strings, or any
+// of its elements matches rightVal, a prepared pattern or a pattern string. ok
+// is false when either value cannot be used.
+func matchPattern(leftVal, rightVal any) (matched, ok bool) {
+	var re *regexp.Regexp
+
+	switch right := rightVal.(type) {
+	case pattern:
+		re = right.re
+	case string:
+		var err error
+		if re, err = compilePattern(right); err != nil {
+			return false, false
+		}
+	default:
+		return false, false
+	}
+
+	return anyString(leftVal, re.MatchString)
+}
+
+// matchesPolicyCheckOperator checks if the left value, a string, matches the
+// RE2 pattern in the right value. When the left value is a slice of strings, it
+// checks if any element matches. The pattern is not anchored, so use ^ and $ to
+// match the whole string.
+var matchesPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	matched, ok := matchPattern(leftVal, rightVal)
+	return ok && matched
+}
+
+// notMatchesPolicyCheckOperator checks if the left value, a string, does not
+// match the RE2 pattern in the right value, or that no element of a slice of
+// strings does. Like matches, it returns false when the left value is not a
+// string or the pattern is invalid.
+var notMatchesPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	matched, ok := matchPattern(leftVal, rightVal)
+	return ok && !matched
+}
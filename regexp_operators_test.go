@@ -0,0 +1,187 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchesPolicyCheckOperator(t *testing.T) {
+	name := "report-2024.pdf"
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"matches", "report-2024.pdf", `^report-\d{4}\.pdf$`, true},
+		{"matches", "report-2024.pdf", `\.docx$`, false},
+		{"matches", "xreport", "report", true}, // Patterns are not anchored
+		{"matches", "123", `^\d+$`, true},      // Numeric strings stay strings
+		{"matches", &name, `\.pdf$`, true},
+		{"matches", 123, `^\d+$`, false},
+		{"matches", nil, ".*", false},
+		{"matches", "abc", "(", false},
+		{"not matches", "report-2024.pdf", `\.docx$`, true},
+		{"not matches", "report-2024.pdf", `\.pdf$`, false},
+		{"not matches", 123, `^\d+$`, false},
+		{"not matches", "abc", "(", false},
+		{"matches", []string{"a.txt", "b.pdf"}, `\.pdf$`, true},
+		{"not matches", []string{"a.txt", "b.pdf"}, `\.pdf$`, false},
+		{"not matches", []string{"a.txt", "b.doc"}, `\.pdf$`, true},
+		{"matches", emailAddress("jane@ourcorp.com"), `@ourcorp\.com$`, true},
+		{"not matches", []emailAddress{"jane@gmail.com"}, `@ourcorp\.com$`, true},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Matches(t *testing.T) {
+	type User struct {
+		Email   string
+		Pattern string
+	}
+
+	policies := []Policy{
+		{
+			Name: "CompanyEmail",
+			Rules: []Rule{
+				{Field: "Email", Operator: "matches", Value: `@example\.com$`},
+				{Field: "Email", Operator: "not matches", Value: `^admin@`},
+			},
+		},
+	}
+
+	program, err := Compile(policies, User{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	tests := []struct {
+		email    string
+		expected bool
+	}{
+		{"jane@example.com", true},
+		{"admin@example.com", false},
+		{"jane@example.org", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			user := User{Email: tt.email}
+
+			if result := policies[0].Evaluate(user); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(user); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+
+	// The pattern can come from another field
+	policy := Policy{Name: "OwnPattern", Rules: []Rule{{Field: "Email", Operator: "matches", Value: FieldRef{Field: "Pattern"}}}}
+	if !policy.Evaluate(User{Email: "jane@example.com", Pattern: "^jane@"}) {
+		t.Errorf("expected a pattern read from a field to match")
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidPattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"invalid pattern", "(unclosed"},
+		{"non string pattern", 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Pattern", Rules: []Rule{{Field: "Email", Operator: "matches", Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ Email string }{Email: "jane@example.com"})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ Email string }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy_InvalidPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected error
+	}{
+		{
+			name:     "invalid pattern",
+			policy:   `{"Name": "Pattern", "Rules": [{"field": "Email", "operator": "matches", "value": "(unclosed"}]}`,
+			expected: ErrInvalidRule,
+		},
+		{
+			name:     "invalid pattern in a group",
+			policy:   `{"Name": "Pattern", "Rules": [{"not": {"field": "Email", "operator": "not matches", "value": "[a-"}}]}`,
+			expected: ErrInvalidRule,
+		},
+		{
+			name:   "valid pattern",
+			policy: `{"Name": "Pattern", "Rules": [{"field": "Email", "operator": "matches", "value": "@example\\.com$"}]}`,
+		},
+		{
+			name:   "operator registered later",
+			policy: `{"Name": "Pattern", "Rules": [{"field": "Email", "operator": "sounds like", "value": "jane"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyFile := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(policyFile, []byte(tt.policy), 0o644); err != nil {
+				t.Fatalf("failed to create policy file: %v", err)
+			}
+
+			policy, err := LoadPolicy(policyFile)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected error %v, but got %v", tt.expected, err)
+			}
+
+			var ruleErr *RuleError
+			if tt.expected != nil && (!errors.As(err, &ruleErr) || ruleErr.Policy != "Pattern") {
+				t.Errorf("expected a *RuleError for the policy, but got %v", err)
+			}
+
+			if tt.expected == nil && policy == nil {
+				t.Errorf("expected a policy, but got nil")
+			}
+		})
+	}
+}
+
+func TestCompilePattern_Caches(t *testing.T) {
+	first, err := compilePattern(`^cached-\d+$`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, _ := compilePattern(`^cached-\d+$`)
+	if first != second {
+		t.Errorf("expected the compiled pattern to be reused")
+	}
+}
@@ -1,7 +1,72 @@
 package go_policy_enforcer
 
+// Rule is a single condition within a policy.
+//
+// A rule is either a comparison or a condition group:
+//   - A comparison reads Field from the resource and compares it to Value using Operator.
+//   - A condition group sets exactly one of All, Any or Not, and leaves Field,
+//     Operator and Value empty. Groups can be nested to any depth.
+//
+// The Rule struct has the following fields:
+// - Field: The dot separated path of the field to compare.
+// - Operator: The operator used to compare the field with Value.
+// - Value: The value to compare against, or a []Rule of nested rules matched against each element of a slice field.
+// - All: Passes when every rule in the group passes. Evaluation stops at the first failing rule.
+// - Any: Passes when at least one rule in the group passes. Evaluation stops at the first passing rule.
+// - Not: Passes when the given rule fails.
 type Rule struct {
-	Field    string `json:"field"`
-	Operator string `json:"operator"`
-	Value    any    `json:"value"`
+	Field    string `json:"field,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    any    `json:"value,omitempty"`
+	All      []Rule `json:"all,omitempty"`
+	Any      []Rule `json:"any,omitempty"`
+	Not      *Rule  `json:"not,omitempty"`
+}
+
+// Group operators reported in RuleResult.Operator for condition groups.
+const (
+	allGroupOperator = "all"
+	anyGroupOperator = "any"
+	notGroupOperator = "not"
+)
+
+// isGroup reports whether the rule is a condition group rather than a comparison.
+func (r Rule) isGroup() bool {
+	return r.All != nil || r.Any != nil || r.Not != nil
+}
+
+// groupOperator returns "all", "any" or "not" for a condition group, or "" for
+// a comparison.
+func (r Rule) groupOperator() string {
+	switch {
+	case r.All != nil:
+		return allGroupOperator
+	case r.Any != nil:
+		return anyGroupOperator
+	case r.Not != nil:
+		return notGroupOperator
+	default:
+		return ""
+	}
+}
+
+// validateGroup ensures a condition group sets exactly one combinator and no
+// comparison fields, so a malformed rule is reported rather than half applied.
+func (r Rule) validateGroup() error {
+	combinators := 0
+	for _, set := range []bool{r.All != nil, r.Any != nil, r.Not != nil} {
+		if set {
+			combinators++
+		}
+	}
+
+	if combinators > 1 {
+		return newEvaluationError(ErrInvalidRule, "a rule may only set one of all, any or not")
+	}
+
+	if r.Field != "" || r.Operator != "" || r.Value != nil {
+		return newEvaluationError(ErrInvalidRule, "a condition group cannot also set field, operator or value")
+	}
+
+	return nil
 }
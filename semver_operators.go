@@ -0,0 +1,419 @@
+package go_policy_enforcer
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version, as described at https://semver.org.
+// Build metadata is dropped, since it does not affect precedence.
+type semver struct {
+	major, minor, patch uint64
+	prerelease          []string
+}
+
+// parseSemver parses a version such as "1.10.2", "v2.0.0-rc.1" or
+// "1.4.0+build.5". The minor and patch numbers can be left out, so "1.2" is
+// 1.2.0, and parts reports how many of the three numbers were given.
+func parseSemver(s string) (v semver, parts int, ok bool) {
+	s = strings.TrimPrefix(s, "v")
+
+	s, build, hasBuild := strings.Cut(s, "+")
+	if hasBuild && !validSemverIdentifiers(build, false) {
+		return semver{}, 0, false
+	}
+
+	s, prerelease, hasPrerelease := strings.Cut(s, "-")
+	if hasPrerelease && !validSemverIdentifiers(prerelease, true) {
+		return semver{}, 0, false
+	}
+
+	numbers := strings.Split(s, ".")
+	if len(numbers) > 3 || (hasPrerelease && len(numbers) != 3) {
+		return semver{}, 0, false
+	}
+
+	fields := []*uint64{&v.major, &v.minor, &v.patch}
+
+	for i, number := range numbers {
+		if !isSemverNumber(number) {
+			return semver{}, 0, false
+		}
+
+		n, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return semver{}, 0, false
+		}
+
+		*fields[i] = n
+	}
+
+	if hasPrerelease {
+		v.prerelease = strings.Split(prerelease, ".")
+	}
+
+	return v, len(numbers), true
+}
+
+// isSemverNumber reports whether s is a number without leading zeros.
+func isSemverNumber(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validSemverIdentifiers reports whether s is a dot separated list of non-empty
+// identifiers made of ASCII letters, digits and hyphens. Numeric prerelease
+// identifiers must not have leading zeros.
+func validSemverIdentifiers(s string, prerelease bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+
+		numeric := true
+
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+
+		if prerelease && numeric && !isSemverNumber(id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare returns -1, 0 or +1 as v has lower, equal or higher precedence than
+// other. A prerelease has lower precedence than its release.
+func (v semver) compare(other semver) int {
+	if c := cmp.Compare(v.major, other.major); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.minor, other.minor); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.patch, other.patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := comparePrereleaseIdentifiers(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(v.prerelease), len(other.prerelease))
+}
+
+// comparePrereleaseIdentifiers compares two prerelease identifiers. Numeric
+// identifiers are compared by value and are lower than alphanumeric ones.
+func comparePrereleaseIdentifiers(a, b string) int {
+	aNumeric, bNumeric := isSemverNumber(a), isSemverNumber(b)
+
+	switch {
+	case aNumeric && bNumeric:
+		// Without leading zeros, a longer number is a larger one
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// toSemver returns v, a version string or a prepared version, as a semver.
+// Strings of any type, such as "type Version string", and values with a text
+// form are parsed, as stringOf describes.
+func toSemver(v any) (semver, bool) {
+	if version, ok := v.(semver); ok {
+		return version, true
+	}
+
+	s, ok := stringOf(v)
+	if !ok {
+		return semver{}, false
+	}
+
+	parsed, _, ok := parseSemver(s)
+	return parsed, ok
+}
+
+// prepareSemver validates the value of a semver comparison rule, which must be
+// a version string.
+func prepareSemver(value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a semver rule must be a string, got %T", value)
+	}
+
+	v, _, ok := parseSemver(s)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "invalid semantic version %q", s)
+	}
+
+	return v, nil
+}
+
+// semverPolicyCheckOperator returns an operator that compares the left and
+// right values as semantic versions and passes when test accepts the result of
+// semver.compare. It fails when either value is not a valid version.
+func semverPolicyCheckOperator(test func(c int) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		left, ok := toSemver(leftVal)
+		if !ok {
+			return false
+		}
+
+		right, ok := toSemver(rightVal)
+
+		return ok && test(left.compare(right))
+	}
+}
+
+// semverEqualsPolicyCheckOperator checks if two versions have the same precedence.
+var semverEqualsPolicyCheckOperator = semverPolicyCheckOperator(func(c int) bool { return c == 0 })
+
+// semverGreaterThanPolicyCheckOperator checks if the left version is newer than the right version.
+var semverGreaterThanPolicyCheckOperator = semverPolicyCheckOperator(func(c int) bool { return c > 0 })
+
+// semverGreaterThanOrEqualsPolicyCheckOperator checks if the left version is not older than the right version.
+var semverGreaterThanOrEqualsPolicyCheckOperator = semverPolicyCheckOperator(func(c int) bool { return c >= 0 })
+
+// semverLessThanPolicyCheckOperator checks if the left version is older than the right version.
+var semverLessThanPolicyCheckOperator = semverPolicyCheckOperator(func(c int) bool { return c < 0 })
+
+// semverLessThanOrEqualsPolicyCheckOperator checks if the left version is not newer than the right version.
+var semverLessThanOrEqualsPolicyCheckOperator = semverPolicyCheckOperator(func(c int) bool { return c <= 0 })
+
+// semverComparator is a single comparison within a semverConstraint, such as
+// ">=1.2.0".
+type semverComparator struct {
+	operator string
+	version  semver
+}
+
+// matches reports whether v satisfies the comparator.
+func (c semverComparator) matches(v semver) bool {
+	r := v.compare(c.version)
+
+	switch c.operator {
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	case "!=":
+		return r != 0
+	default:
+		return r == 0
+	}
+}
+
+// semverConstraint is the prepared value of a "semver_satisfies" rule. A version
+// satisfies it when it satisfies every comparator of any alternative.
+type semverConstraint struct {
+	alternatives [][]semverComparator
+}
+
+// matches reports whether v satisfies the constraint.
+func (c semverConstraint) matches(v semver) bool {
+	for _, comparators := range c.alternatives {
+		satisfied := true
+
+		for _, comparator := range comparators {
+			if !comparator.matches(v) {
+				satisfied = false
+				break
+			}
+		}
+
+		if satisfied {
+			return true
+		}
+	}
+
+	return false
+}
+
+// semverOperators are the comparison operators of a constraint, longest first
+// so that ">=" is not read as ">".
+var semverOperators = []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"}
+
+// parseSemverConstraint parses a constraint such as ">=1.2.0 <2.0.0". Space or
+// comma separated comparators must all be satisfied, and "||" separates
+// alternatives. Besides the comparison operators, "~1.2.3" allows patch updates
+// (>=1.2.3 <1.3.0), "^1.2.3" allows updates that do not change the first
+// non-zero number (>=1.2.3 <2.0.0), a bare version must be equal and "*"
+// matches any version.
+func parseSemverConstraint(s string) (semverConstraint, bool) {
+	var constraint semverConstraint
+
+	for _, alternative := range strings.Split(s, "||") {
+		tokens := strings.FieldsFunc(alternative, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t'
+		})
+
+		if len(tokens) == 0 {
+			return semverConstraint{}, false
+		}
+
+		var comparators []semverComparator
+
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+
+			// Allow a space between an operator and its version, as in ">= 1.2.0"
+			if isSemverOperator(token) && i+1 < len(tokens) {
+				i++
+				token += tokens[i]
+			}
+
+			parsed, ok := parseSemverComparator(token)
+			if !ok {
+				return semverConstraint{}, false
+			}
+
+			comparators = append(comparators, parsed...)
+		}
+
+		constraint.alternatives = append(constraint.alternatives, comparators)
+	}
+
+	return constraint, true
+}
+
+// isSemverOperator reports whether token is only a comparison operator.
+func isSemverOperator(token string) bool {
+	for _, operator := range semverOperators {
+		if token == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSemverComparator parses a single comparator, expanding "~" and "^" into
+// the pair of comparators that bound them.
+func parseSemverComparator(token string) ([]semverComparator, bool) {
+	if token == "*" {
+		return []semverComparator{{operator: ">=", version: semver{}}}, true
+	}
+
+	operator := "="
+
+	for _, op := range semverOperators {
+		if strings.HasPrefix(token, op) {
+			operator, token = op, token[len(op):]
+			break
+		}
+	}
+
+	v, parts, ok := parseSemver(token)
+	if !ok {
+		return nil, false
+	}
+
+	switch operator {
+	case "~":
+		upper := semver{major: v.major + 1}
+		if parts > 1 {
+			upper = semver{major: v.major, minor: v.minor + 1}
+		}
+
+		return []semverComparator{{">=", v}, {"<", upper}}, true
+	case "^":
+		var upper semver
+
+		switch {
+		case v.major > 0 || parts == 1:
+			upper = semver{major: v.major + 1}
+		case v.minor > 0 || parts == 2:
+			upper = semver{minor: v.minor + 1}
+		default:
+			upper = semver{patch: v.patch + 1}
+		}
+
+		return []semverComparator{{">=", v}, {"<", upper}}, true
+	case "==":
+		operator = "="
+	}
+
+	return []semverComparator{{operator, v}}, true
+}
+
+// prepareSemverConstraint validates the value of a "semver_satisfies" rule,
+// which must be a constraint string.
+func prepareSemverConstraint(value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a semver_satisfies rule must be a string, got %T", value)
+	}
+
+	constraint, ok := parseSemverConstraint(s)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "invalid version constraint %q", s)
+	}
+
+	return constraint, nil
+}
+
+// semverSatisfiesPolicyCheckOperator checks if the left value, a semantic
+// version, satisfies the constraint in the right value, such as
+// ">=1.2.0 <2.0.0". See parseSemverConstraint for the constraint syntax.
+var semverSatisfiesPolicyCheckOperator = func(leftVal, rightVal any) bool {
+	v, ok := toSemver(leftVal)
+	if !ok {
+		return false
+	}
+
+	constraint, ok := rightVal.(semverConstraint)
+	if !ok {
+		s, isString := rightVal.(string)
+		if !isString {
+			return false
+		}
+
+		if constraint, ok = parseSemverConstraint(s); !ok {
+			return false
+		}
+	}
+
+	return constraint.matches(v)
+}
@@ -0,0 +1,188 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		version string
+		parts   int
+		ok      bool
+	}{
+		{"1.10.2", 3, true},
+		{"v2.0.0", 3, true},
+		{"1.0.0-rc.1", 3, true},
+		{"1.0.0-alpha-1+build.5", 3, true},
+		{"1.2", 2, true},
+		{"1", 1, true},
+		{"1.2.3.4", 0, false},
+		{"01.2.3", 0, false},
+		{"1.2.3-01", 0, false},
+		{"1.2-rc.1", 0, false},
+		{"1.2.3-", 0, false},
+		{"1.2.3+", 0, false},
+		{"1.2.3-rc..1", 0, false},
+		{"1.2.x", 0, false},
+		{"", 0, false},
+		{"99999999999999999999.0.0", 0, false},
+	}
+
+	for _, tt := range tests {
+		_, parts, ok := parseSemver(tt.version)
+		if ok != tt.ok || parts != tt.parts {
+			t.Errorf("parseSemver(%q) = %d, %v; want %d, %v", tt.version, parts, ok, tt.parts, tt.ok)
+		}
+	}
+}
+
+func TestSemverPrecedence(t *testing.T) {
+	// In increasing order of precedence, from https://semver.org
+	versions := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.9.0", "1.10.0", "2.0.0",
+	}
+
+	for i := 1; i < len(versions); i++ {
+		result, err := evaluatePolicyCheckOperator("semver_lt", versions[i-1], versions[i])
+		if err != nil || !result {
+			t.Errorf("expected %s semver_lt %s, but got %v (%v)", versions[i-1], versions[i], result, err)
+		}
+	}
+}
+
+// agentVersion is a named string type holding a version.
+type agentVersion string
+
+func TestSemverPolicyCheckOperators(t *testing.T) {
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"semver_gt", "1.10.0", "1.9.0", true},
+		{"semver_gt", "1.9.0", "1.10.0", false},
+		{"semver_gte", "1.10.0", "1.10.0", true},
+		{"semver_lt", "1.0.0-rc.1", "1.0.0", true},
+		{"semver_lte", "2.0.0", "1.99.99", false},
+		{"semver_eq", "v1.2.0", "1.2", true},
+		{"semver_eq", "1.2.0+build.1", "1.2.0+build.2", true},
+		{"semver_eq", "1.2.0", "1.2.1", false},
+		{"semver_gt", "latest", "1.0.0", false},
+		{"semver_gt", 2, "1.0.0", false},
+		{"semver_satisfies", "1.5.0", ">=1.2.0 <2.0.0", true},
+		{"semver_satisfies", "2.0.0", ">=1.2.0 <2.0.0", false},
+		{"semver_satisfies", "1.5.0", ">= 1.2.0, < 2.0.0", true},
+		{"semver_satisfies", "1.2.9", "~1.2.3", true},
+		{"semver_satisfies", "1.3.0", "~1.2.3", false},
+		{"semver_satisfies", "1.9.0", "~1", true},
+		{"semver_satisfies", "1.9.0", "^1.2.3", true},
+		{"semver_satisfies", "2.0.0", "^1.2.3", false},
+		{"semver_satisfies", "0.2.9", "^0.2.3", true},
+		{"semver_satisfies", "0.3.0", "^0.2.3", false},
+		{"semver_satisfies", "0.0.4", "^0.0.3", false},
+		{"semver_satisfies", "3.1.0", "^1.0.0 || ^3.0.0", true},
+		{"semver_satisfies", "2.1.0", "^1.0.0 || ^3.0.0", false},
+		{"semver_satisfies", "1.4.0", "1.4.0", true},
+		{"semver_satisfies", "1.4.0", "!=1.4.0", false},
+		{"semver_satisfies", "0.0.1", "*", true},
+		{"semver_satisfies", "not a version", "*", false},
+		{"semver_satisfies", "1.0.0", ">=", false},
+		{"semver_gt", agentVersion("1.10.0"), "1.9.0", true},
+		{"semver_eq", agentVersion("1.2.0"), agentVersion("v1.2"), true},
+		{"semver_satisfies", agentVersion("1.5.0"), ">=1.2.0 <2.0.0", true},
+		{"semver_satisfies", agentVersion("2.5.0"), ">=1.2.0 <2.0.0", false},
+		{"semver_lt", release{1, 10}, "1.11.0", true}, // Parsed from its text, "v1.10"
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_Semver(t *testing.T) {
+	type Agent struct {
+		AgentVersion string
+		Version      agentVersion
+	}
+
+	policies := []Policy{
+		{
+			Name: "SupportedAgent",
+			Rules: []Rule{
+				{Field: "AgentVersion", Operator: "semver_gte", Value: "1.9.0"},
+				{Field: "AgentVersion", Operator: "semver_satisfies", Value: "<2.0.0 || >=3.1.0"},
+				{Field: "Version", Operator: "semver_satisfies", Value: "<2.0.0 || >=3.1.0"},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Agent{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.10.2", true},
+		{"1.8.9", false},
+		{"2.4.0", false},
+		{"3.1.0", true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			agent := Agent{AgentVersion: tt.version, Version: agentVersion(tt.version)}
+
+			if result := policies[0].Evaluate(agent); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(agent); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidSemver(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{"invalid version", "semver_gt", "1.x"},
+		{"non string version", "semver_eq", 1.2},
+		{"invalid constraint", "semver_satisfies", ">=1.2.0 <two"},
+		{"empty alternative", "semver_satisfies", ">=1.2.0 ||"},
+		{"non string constraint", "semver_satisfies", []string{">=1.2.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Version", Rules: []Rule{{Field: "Version", Operator: tt.operator, Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ Version string }{Version: "1.0.0"})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ Version string }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}
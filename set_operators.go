@@ -0,0 +1,284 @@
+package go_policy_enforcer
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// setOperatorOptions are the options of the set operators, which are given
+// slices as they are.
+var setOperatorOptions = operatorOptions{prepare: prepareValueSet, exact: true, handlesSlices: true}
+
+// valueSet is a set of values, keyed by setKey, used by the set operators. It
+// is also the prepared value of their rules.
+type valueSet struct {
+	// keys maps the key of each element to the element.
+	keys map[any]any
+
+	// texts maps the text of each number with a text form, such as an enum
+	// implementing fmt.Stringer, to the element. Such numbers also equal their
+	// text, as they do with "==" and "in".
+	texts map[string]any
+
+	// comparables is true when an element is Comparable.
+	comparables bool
+}
+
+// add adds elem, whose key is key, to the set.
+func (s *valueSet) add(key, elem any) {
+	s.keys[key] = elem
+
+	if text, ok := numberText(elem); ok {
+		if s.texts == nil {
+			s.texts = map[string]any{}
+		}
+
+		s.texts[text] = elem
+	}
+
+	if _, ok := utils.ValueAs[Comparable](elem); ok {
+		s.comparables = true
+	}
+}
+
+// has reports whether the set contains elem, whose key is key. Numbers with a
+// text form are also equal to their text, and Comparable values to the
+// elements they compare equal to, whatever their keys.
+func (s valueSet) has(key, elem any) bool {
+	if _, ok := s.keys[key]; ok {
+		return true
+	}
+
+	if text, ok := key.(string); ok {
+		if _, ok := s.texts[text]; ok {
+			return true
+		}
+	}
+
+	if text, ok := numberText(elem); ok {
+		if _, ok := s.keys[text]; ok {
+			return true
+		}
+	}
+
+	if _, ok := utils.ValueAs[Comparable](elem); !ok && !s.comparables {
+		return false
+	}
+
+	for _, other := range s.keys {
+		if c, ok, handled := compareCustom(elem, other); handled && ok && c == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setKey returns the key v is stored under in a valueSet. Numbers of any type
+// with the same value share a key, so 1 and 1.0 decoded from JSON are the same
+// element, as are the float64 19.99 and the decimal "19.99" as a json.Number or
+// big.Rat, as do times that are the same instant. Other values implementing
+// encoding.TextMarshaler or fmt.Stringer are keyed by their text, and
+// Comparable values by themselves. ok is false for values that cannot be map
+// keys, such as slices.
+//
+// Keys are tried in the order "==" and "in" compare values: as numbers first,
+// then as text. A number with a text form, such as "type Status int" with a
+// String method, is keyed as a number, and numberText gives the text it also
+// matches.
+func setKey(v any) (any, bool) {
+	v = utils.DereferencePointer(v)
+
+	if _, ok := utils.ValueAs[Comparable](v); ok {
+		return v, reflect.TypeOf(v).Comparable()
+	}
+
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Round(0), true
+	}
+
+	if n, ok := numberOf(v); ok {
+		return numberKey(n), true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil, true
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Bool:
+		return rv.Bool(), true
+	}
+
+	if text, ok := textOf(v); ok {
+		return text, true
+	}
+
+	if !rv.Comparable() {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// numberText returns the text of v when it is a number with a text form, such
+// as an enum implementing fmt.Stringer, which equals both its value and its
+// text. A json.Number has no such text, as stringOf describes.
+func numberText(v any) (string, bool) {
+	v = utils.DereferencePointer(v)
+
+	if _, ok := numberOf(v); !ok {
+		return "", false
+	}
+
+	return stringOf(v)
+}
+
+// decimalKey is the key of a number that is not a whole number held by an
+// int64 or uint64, in the form returned by big.Rat.RatString.
+type decimalKey string
+
+// numberKey returns the key of a number returned by numberOf: the number as an
+// int64 or uint64 for whole numbers that fit one, and a decimalKey otherwise.
+// NaN and infinities are their own keys.
+func numberKey(n any) any {
+	switch n := n.(type) {
+	case int64:
+		return n
+	case uint64:
+		if n > math.MaxInt64 {
+			return n
+		}
+		return int64(n)
+	case float64:
+		switch {
+		case math.IsNaN(n) || math.IsInf(n, 0):
+			return n
+		case n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64:
+			return int64(n)
+		case n == math.Trunc(n) && n >= 0 && n < math.MaxUint64:
+			return uint64(n)
+		}
+	}
+
+	r, _, _ := toRat(n)
+
+	if r.IsInt() && (r.Num().IsInt64() || r.Num().IsUint64()) {
+		whole, _ := bigIntNumber(r.Num())
+		return numberKey(whole)
+	}
+
+	return decimalKey(r.RatString())
+}
+
+// toValueSet returns the elements of v, a slice or array, as a set. Any other
+// value is a set of that one value, and nil is the empty set. Duplicates are
+// ignored. ok is false when an element cannot be stored in a set.
+func toValueSet(v any) (valueSet, bool) {
+	if set, ok := v.(valueSet); ok {
+		return set, true
+	}
+
+	set := valueSet{keys: map[any]any{}}
+
+	rv := reflect.ValueOf(v)
+
+	switch {
+	case v == nil:
+		return set, true
+	case rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array:
+		key, ok := setKey(v)
+		set.add(key, v)
+		return set, ok
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i).Interface()
+
+		key, ok := setKey(elem)
+		if !ok {
+			return valueSet{}, false
+		}
+
+		set.add(key, elem)
+	}
+
+	return set, true
+}
+
+// prepareValueSet validates the value of a set operator rule and builds its set.
+func prepareValueSet(value any) (any, error) {
+	set, ok := toValueSet(value)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a set rule must be a list of comparable values, got %v", value)
+	}
+
+	return set, nil
+}
+
+// setPolicyCheckOperator returns an operator that converts both values to sets
+// with toValueSet and passes when test accepts them. It fails when either value
+// cannot be converted.
+func setPolicyCheckOperator(test func(left, right valueSet) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		left, ok := toValueSet(leftVal)
+		if !ok {
+			return false
+		}
+
+		right, ok := toValueSet(rightVal)
+
+		return ok && test(left, right)
+	}
+}
+
+// isSubset reports whether every element of a is in b.
+func isSubset(a, b valueSet) bool {
+	for key, elem := range a.keys {
+		if !b.has(key, elem) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// intersects reports whether a and b have an element in common.
+func intersects(a, b valueSet) bool {
+	if len(a.keys) > len(b.keys) {
+		a, b = b, a
+	}
+
+	for key, elem := range a.keys {
+		if b.has(key, elem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subsetOfPolicyCheckOperator checks if every element of the left value is in
+// the right value.
+var subsetOfPolicyCheckOperator = setPolicyCheckOperator(isSubset)
+
+// supersetOfPolicyCheckOperator checks if every element of the right value is
+// in the left value.
+var supersetOfPolicyCheckOperator = setPolicyCheckOperator(func(left, right valueSet) bool {
+	return isSubset(right, left)
+})
+
+// intersectsPolicyCheckOperator checks if the left and right values have at
+// least one element in common.
+var intersectsPolicyCheckOperator = setPolicyCheckOperator(intersects)
+
+// disjointPolicyCheckOperator checks if the left and right values have no
+// elements in common.
+var disjointPolicyCheckOperator = setPolicyCheckOperator(func(left, right valueSet) bool {
+	return !intersects(left, right)
+})
@@ -0,0 +1,149 @@
+package go_policy_enforcer
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// accountStatus is an enum that is both a number and, through fmt.Stringer, a
+// name.
+type accountStatus int
+
+func (s accountStatus) String() string {
+	return [...]string{"Pending", "Active", "Closed"}[s]
+}
+
+func TestSetPolicyCheckOperators(t *testing.T) {
+	type Scope string
+
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"subset_of", []string{"read", "write"}, []any{"read", "write", "admin"}, true},
+		{"subset_of", []string{"read", "delete"}, []any{"read", "write"}, false},
+		{"subset_of", []string{"read", "read"}, []string{"read"}, true}, // Duplicates are ignored
+		{"subset_of", []string{}, []string{"read"}, true},
+		{"subset_of", []string(nil), []string{}, true},
+		{"subset_of", "read", []string{"read", "write"}, true}, // A single value is a set of one
+		{"subset_of", []int{1, 2}, []any{1.0, 2.0, 3.0}, true}, // Numbers from JSON
+		{"subset_of", []uint8{1, 2}, []int64{1, 2}, true},
+		{"subset_of", []float64{1.5}, []any{1.5}, true},
+		{"subset_of", []float64{1.5}, []any{1}, false},
+		{"subset_of", []Scope{"read"}, []string{"read"}, true},
+		{"subset_of", []string{"1"}, []any{1}, false}, // Strings are not numbers
+		{"superset_of", []string{"read", "write", "admin"}, []string{"read", "admin"}, true},
+		{"superset_of", []string{"read"}, []string{"read", "admin"}, false},
+		{"superset_of", []string{"read"}, []string{}, true},
+		{"contains_all", []string{"read", "write"}, []string{"write", "write"}, true},
+		{"contains_all", []string{"read", "write"}, "write", true},
+		{"intersects", []string{"read", "write"}, []string{"admin", "write"}, true},
+		{"intersects", []string{"read", "write"}, []string{"admin"}, false},
+		{"intersects", []string{"read"}, []string{}, false},
+		{"contains_any", []int{1, 2, 3}, []any{3.0, 4.0}, true},
+		{"contains_any", []time.Time{noon.In(time.FixedZone("JST", 9*60*60))}, []time.Time{noon}, true},
+		{"disjoint", []string{"read", "write"}, []string{"admin"}, true},
+		{"disjoint", []string{"read", "write"}, []string{"write"}, false},
+		{"disjoint", []string{}, []string{}, true},
+		{"subset_of", []any{json.Number("0.10"), json.Number("2")}, []any{0.1, 2, 3}, true}, // Numbers by value
+		{"intersects", []*big.Rat{big.NewRat(1999, 100)}, []any{json.Number("19.99")}, true},
+		{"intersects", []*big.Rat{big.NewRat(1999, 100)}, []any{json.Number("19.991")}, false},
+		{"subset_of", []accountStatus{1}, []any{"Active", "Pending"}, true}, // As "in" compares them
+		{"subset_of", []accountStatus{1, 2}, []any{"Active", "Pending"}, false},
+		{"subset_of", []any{"Active"}, []accountStatus{0, 1}, true},
+		{"subset_of", []accountStatus{1}, []any{1.0}, true}, // Still a number
+		{"intersects", []accountStatus{1}, []string{"Active"}, true},
+		{"intersects", []string{"Closed", "Active"}, []accountStatus{1}, true},
+		{"intersects", []accountStatus{2}, []string{"Active"}, false},
+		{"disjoint", []accountStatus{0}, []string{"Active", "Closed"}, true},
+		{"subset_of", []json.Number{"7"}, []string{"7"}, false}, // A json.Number is not text
+		{"subset_of", [][]string{{"a"}}, []string{"a"}, false},
+		{"intersects", []string{"a"}, [][]string{{"a"}}, false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_SetOperators(t *testing.T) {
+	type Token struct {
+		Scopes   []string
+		Roles    []string
+		Labels   map[string]any
+		Statuses []accountStatus
+	}
+
+	policies := []Policy{
+		{
+			Name: "ScopedToken",
+			Rules: []Rule{
+				{Field: "Scopes", Operator: "subset_of", Value: []any{"read", "write", "list"}},
+				{Field: "Roles", Operator: "contains_any", Value: []any{"editor", "owner"}},
+				{Field: "Roles", Operator: "disjoint", Value: []any{"suspended"}},
+				{Field: "Labels.levels", Operator: "contains_all", Value: []any{1.0, 2.0}},
+				{Field: "Statuses", Operator: "intersects", Value: []any{"Active"}},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Token{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	levels := map[string]any{"levels": []int{1, 2, 3}}
+	active := []accountStatus{0, 1}
+
+	tests := []struct {
+		name     string
+		token    Token
+		expected bool
+	}{
+		{"valid", Token{Scopes: []string{"read", "list"}, Roles: []string{"editor"}, Labels: levels, Statuses: active}, true},
+		{"extra scope", Token{Scopes: []string{"read", "delete"}, Roles: []string{"editor"}, Labels: levels, Statuses: active}, false},
+		{"no role", Token{Scopes: []string{"read"}, Roles: []string{"viewer"}, Labels: levels, Statuses: active}, false},
+		{"suspended", Token{Scopes: []string{"read"}, Roles: []string{"owner", "suspended"}, Labels: levels, Statuses: active}, false},
+		{"inactive", Token{Scopes: []string{"read"}, Roles: []string{"owner"}, Labels: levels, Statuses: []accountStatus{2}}, false},
+		{"missing level", Token{Scopes: []string{"read"}, Roles: []string{"owner"}, Labels: map[string]any{"levels": []int{1}}, Statuses: active}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := policies[0].Evaluate(tt.token); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(tt.token); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidSet(t *testing.T) {
+	policy := Policy{Name: "Set", Rules: []Rule{{Field: "Roles", Operator: "subset_of", Value: []any{[]string{"a"}}}}}
+
+	if _, err := policy.EvaluateE(struct{ Roles []string }{}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule, but got %v", err)
+	}
+
+	if _, err := Compile([]Policy{policy}, struct{ Roles []string }{}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+	}
+}
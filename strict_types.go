@@ -0,0 +1,281 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// The kinds of value compared by strict type checks. Values of any other type
+// are their own kind, named after the type.
+const (
+	numberKind   = "number"
+	stringKind   = "string"
+	boolKind     = "bool"
+	timeKind     = "time"
+	durationKind = "duration"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	bigIntType     = reflect.TypeOf(big.Int{})
+	bigRatType     = reflect.TypeOf(big.Rat{})
+	bigFloatType   = reflect.TypeOf(big.Float{})
+)
+
+// kindOfType returns the kind of the values of type t, so that every integer and
+// floating point type, including named types, json.Number and the math/big
+// numbers, is a "number", and other types implementing encoding.TextMarshaler
+// or fmt.Stringer are a "string". It returns "" when t is nil, an interface or
+// Comparable, whose values can be compared with values of any kind.
+func kindOfType(t reflect.Type) string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == nil || t.Kind() == reflect.Interface || implements(t, comparableType):
+		return ""
+	case t == timeType:
+		return timeKind
+	case t == durationType:
+		return durationKind
+	case t == jsonNumberType || t == bigIntType || t == bigRatType || t == bigFloatType:
+		return numberKind
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return numberKind
+	case reflect.String:
+		return stringKind
+	case reflect.Bool:
+		return boolKind
+	}
+
+	if implements(t, textMarshalerType) || implements(t, stringerType) {
+		return stringKind
+	}
+
+	return t.String()
+}
+
+// kindOfValue returns the kind of the value held by v, following pointers and
+// interfaces. nil has no kind.
+func kindOfValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return ""
+	}
+
+	return kindOfType(v.Type())
+}
+
+// operandKinds returns the distinct kinds of v, or of its elements when v is a
+// slice or array, as the slice comparisons and "in" compare elements.
+func operandKinds(v any) []string {
+	rv := reflect.ValueOf(utils.DereferencePointer(v))
+
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || isCustomValue(rv.Interface()) {
+		return []string{kindOfValue(rv)}
+	}
+
+	var kinds []string
+	for i := 0; i < rv.Len(); i++ {
+		if kind := kindOfValue(rv.Index(i)); !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds
+}
+
+// fieldKinds returns the kinds of the values of a field of type t, the kind of
+// its elements when it is a slice or array that is not compared as a whole,
+// such as a UUID implementing encoding.TextMarshaler.
+func fieldKinds(t reflect.Type) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isCustomType(t) {
+		t = t.Elem()
+	}
+
+	return []string{kindOfType(t)}
+}
+
+// compatibleKinds reports whether values of kinds a and b can be compared with
+// strict types. Times and durations can be compared with strings, as that is
+// how they are written in JSON policies, and nil can be compared with anything.
+func compatibleKinds(a, b string) bool {
+	switch {
+	case a == "" || b == "" || a == b:
+		return true
+	case a == stringKind:
+		return b == timeKind || b == durationKind
+	case b == stringKind:
+		return a == timeKind || a == durationKind
+	default:
+		return false
+	}
+}
+
+// checkKinds returns an error wrapping ErrTypeMismatch when any of the left
+// kinds cannot be compared with any of the right kinds.
+func checkKinds(left, right []string) error {
+	for _, l := range left {
+		for _, r := range right {
+			if !compatibleKinds(l, r) {
+				return newEvaluationError(ErrTypeMismatch, "cannot compare a %s with a %s with strict types", l, r)
+			}
+		}
+	}
+
+	return nil
+}
+
+// strictComparison wraps a prepared comparison so that it first checks the
+// field value can be compared, with strict types, with values of kinds, the
+// kinds of the rule's value returned by operatorOptions.valueKinds.
+func strictComparison(prepared func(ctx context.Context, leftVal any) (bool, error), kinds []string) func(ctx context.Context, leftVal any) (bool, error) {
+	return func(ctx context.Context, leftVal any) (bool, error) {
+		if err := checkKinds(operandKinds(leftVal), kinds); err != nil {
+			return false, err
+		}
+
+		return prepared(ctx, leftVal)
+	}
+}
+
+// StrictTypesChange describes a rule whose result for a resource changes when
+// its policy is evaluated with strict types.
+//
+// The StrictTypesChange struct has the following fields:
+// - Policy: The name of the policy containing the rule.
+// - Rule: The rule whose result changes. Rules within condition groups are reported individually.
+// - Resource: The index of the resource, in the order given to StrictTypesReport.
+// - Lenient: The rule's result when values are coerced, as they are by default.
+// - Strict: The rule's result with strict types. Its Err wraps ErrTypeMismatch
+// when the values compared are of incompatible types.
+type StrictTypesChange struct {
+	Policy   string
+	Rule     Rule
+	Resource int
+	Lenient  RuleResult
+	Strict   RuleResult
+}
+
+// String describes the change on a single line.
+func (c StrictTypesChange) String() string {
+	return fmt.Sprintf("policy %q: rule '%s %s %v' on resource %d (got %v): %s, with strict types %s",
+		c.Policy, c.Rule.Field, c.Rule.Operator, c.Rule.Value, c.Resource, c.Lenient.Value, ruleOutcome(c.Lenient), ruleOutcome(c.Strict))
+}
+
+// ruleOutcome describes a rule result as pass, fail or the error it failed with.
+func ruleOutcome(r RuleResult) string {
+	if r.Err != nil {
+		return fmt.Sprintf("error (%v)", r.Err)
+	}
+
+	return passFail(r.Passed)
+}
+
+// StrictTypesReport evaluates every rule of the policies against each resource,
+// with and without strict types, and lists the rules whose results differ. Use
+// it before enabling strict types with WithStrictTypes or Policy.StrictTypes,
+// with a sample of real resources, to find the rules that rely on coercion.
+//
+// Rules are evaluated on their own, so a rule is reported even when its policy
+// would have stopped at an earlier rule. Resources that are not structs are
+// skipped.
+//
+// Parameters:
+// - policies: The policies to check.
+// - resources: The resources to evaluate the policies against.
+// - options: Optional settings, such as WithOperatorRegistry or WithClock.
+//
+// Returns:
+// - []StrictTypesChange: The rules whose results change, by policy, then resource.
+func StrictTypesReport(policies []Policy, resources []any, options ...PolicyEnforcerOption) []StrictTypesChange {
+	settings := PolicyEnforcer{}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	var (
+		ctx     = withClock(context.Background(), settings.Clock)
+		lenient = compiler{operators: settings.Operators}
+		strict  = compiler{operators: settings.Operators, strictTypes: true}
+		changes []StrictTypesChange
+	)
+
+	for i := range policies {
+		policy := &policies[i]
+
+		for j, resource := range resources {
+			v, ok := resourceRootValue(resource)
+			if !ok {
+				continue
+			}
+
+			visit := func(rule *Rule) {
+				lenientRule, _ := lenient.compileRule(rule, nil)
+				strictRule, _ := strict.compileRule(rule, nil)
+
+				lenientResult := lenientRule.evaluate(ctx, v, false)
+				strictResult := strictRule.evaluate(ctx, v, false)
+
+				if lenientResult.Passed != strictResult.Passed || (lenientResult.Err == nil) != (strictResult.Err == nil) {
+					changes = append(changes, StrictTypesChange{
+						Policy:   policy.Name,
+						Rule:     *rule,
+						Resource: j,
+						Lenient:  lenientResult,
+						Strict:   strictResult,
+					})
+				}
+			}
+
+			visitComparisons(policy.Target, visit)
+			visitComparisons(policy.Rules, visit)
+		}
+	}
+
+	return changes
+}
+
+// visitComparisons calls visit for every rule that is not a condition group,
+// descending into the rules of groups.
+func visitComparisons(rules []Rule, visit func(rule *Rule)) {
+	for i := range rules {
+		rule := &rules[i]
+
+		switch {
+		case rule.All != nil:
+			visitComparisons(rule.All, visit)
+		case rule.Any != nil:
+			visitComparisons(rule.Any, visit)
+		case rule.Not != nil:
+			visitComparisons([]Rule{*rule.Not}, visit)
+		default:
+			visit(rule)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type strictAccount struct {
+	Zip       string
+	AccountID string
+	Status    strictStatus
+	Balance   strictCents
+	Logins    int
+	Roles     []string
+	CreatedAt time.Time
+	Timeout   time.Duration
+	Active    bool
+}
+
+type strictStatus string
+
+type strictCents int64
+
+func newStrictAccount() strictAccount {
+	return strictAccount{
+		Zip:       "007",
+		AccountID: "1e3",
+		Status:    "active",
+		Balance:   2500,
+		Logins:    5,
+		Roles:     []string{"admin", "editor"},
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Timeout:   90 * time.Minute,
+		Active:    true,
+	}
+}
+
+func TestPolicy_Evaluate_StrictTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		lenient  bool
+		expected bool
+		err      error
+	}{
+		{"string and number", Rule{Field: "Zip", Operator: "==", Value: 7}, true, false, ErrTypeMismatch},
+		{"exponent string and number", Rule{Field: "AccountID", Operator: "==", Value: 1000}, true, false, ErrTypeMismatch},
+		{"numeric strings are not parsed", Rule{Field: "Zip", Operator: "==", Value: "7"}, true, false, nil},
+		{"same string", Rule{Field: "Zip", Operator: "==", Value: "007"}, true, true, nil},
+		{"not equal strings", Rule{Field: "Zip", Operator: "!=", Value: "7"}, false, true, nil},
+		{"strings ordered lexically", Rule{Field: "Zip", Operator: "<", Value: "1"}, false, true, nil},
+		{"strings in a range ordered lexically", Rule{Field: "Zip", Operator: "between", Value: []any{"0", "1"}}, false, true, nil},
+		{"string in a range of numbers", Rule{Field: "Zip", Operator: "between", Value: []any{1, 10}}, true, false, ErrTypeMismatch},
+		{"string in a range with a number bound", Rule{Field: "Zip", Operator: "between", Value: []any{"0", 10}}, true, false, ErrTypeMismatch},
+		{"number in a range of numbers", Rule{Field: "Logins", Operator: "between", Value: []any{1, 10}}, true, true, nil},
+		{"time in a range of strings", Rule{Field: "CreatedAt", Operator: "between", Value: []any{"2024-01-01T00:00:00Z", "2025-01-01T00:00:00Z"}}, true, true, nil},
+		{"string ordered with number", Rule{Field: "AccountID", Operator: ">", Value: 999}, true, false, ErrTypeMismatch},
+		{"named string type", Rule{Field: "Status", Operator: "==", Value: "active"}, true, true, nil},
+		{"named number and float", Rule{Field: "Balance", Operator: ">=", Value: 2500.0}, true, true, nil},
+		{"int and float", Rule{Field: "Logins", Operator: "==", Value: 5.0}, true, true, nil},
+		{"number and numeric string", Rule{Field: "Logins", Operator: "==", Value: "5"}, true, false, ErrTypeMismatch},
+		{"bool and string", Rule{Field: "Active", Operator: "==", Value: "true"}, true, false, ErrTypeMismatch},
+		{"bool", Rule{Field: "Active", Operator: "==", Value: true}, true, true, nil},
+		{"in strings", Rule{Field: "Zip", Operator: "in", Value: []any{"007", "008"}}, true, true, nil},
+		{"in numbers", Rule{Field: "Zip", Operator: "in", Value: []any{7, 8}}, false, false, ErrTypeMismatch},
+		{"list of strings", Rule{Field: "Roles", Operator: "==", Value: []string{"editor", "admin"}}, true, true, nil},
+		{"time and string", Rule{Field: "CreatedAt", Operator: ">", Value: "2024-01-01T00:00:00Z"}, true, true, nil},
+		{"duration and string", Rule{Field: "Timeout", Operator: "<", Value: "2h"}, true, true, nil},
+		{"time and number", Rule{Field: "CreatedAt", Operator: ">", Value: 0}, false, false, ErrTypeMismatch},
+		{"exact operators are unaffected", Rule{Field: "Zip", Operator: "starts_with", Value: "00"}, true, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lenient := Policy{Name: "Account", Rules: []Rule{tt.rule}}
+			if result, err := lenient.EvaluateE(newStrictAccount()); result != tt.lenient || err != nil {
+				t.Errorf("EvaluateE() without strict types = %v, %v; want %v, nil", result, err, tt.lenient)
+			}
+
+			strict := Policy{Name: "Account", Rules: []Rule{tt.rule}, StrictTypes: true}
+			result, err := strict.EvaluateE(newStrictAccount())
+
+			if result != tt.expected {
+				t.Errorf("EvaluateE() with strict types = %v; want %v", result, tt.expected)
+			}
+
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Errorf("EvaluateE() with strict types error = %v; want %v", err, tt.err)
+			}
+
+			// Compiled programs report mismatches found from the field's type up front
+			program, err := Compile([]Policy{strict}, strictAccount{})
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Compile() error = %v; want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			if result := program.Enforce(newStrictAccount()); result != tt.expected {
+				t.Errorf("compiled Enforce() = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPolicyEnforcer_WithStrictTypes(t *testing.T) {
+	policies := []Policy{{Name: "Zip", Rules: []Rule{{Field: "Zip", Operator: "==", Value: 7}}}}
+
+	if !NewPolicyEnforcer(&policies).Enforce(newStrictAccount()) {
+		t.Error("Enforce() without strict types = false; want true")
+	}
+
+	allowed, err := NewPolicyEnforcer(&policies, WithStrictTypes()).EnforceE(newStrictAccount())
+	if allowed || !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("EnforceE() with strict types = %v, %v; want false, ErrTypeMismatch", allowed, err)
+	}
+
+	var ruleErr *RuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Policy != "Zip" {
+		t.Errorf("EnforceE() error = %v; want a *RuleError for policy Zip", err)
+	}
+
+	if _, err := Compile(policies, strictAccount{}, WithStrictTypes()); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Compile() error = %v; want ErrTypeMismatch", err)
+	}
+
+	// Rules comparing fields whose type is only known at evaluation time are
+	// checked as they are evaluated
+	program, err := Compile([]Policy{{Name: "Ref", Rules: []Rule{
+		{Field: "Zip", Operator: "==", Value: FieldRef{Field: "Logins"}},
+	}}}, strictAccount{}, WithStrictTypes())
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if _, err := program.EnforceE(newStrictAccount()); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("compiled EnforceE() error = %v; want ErrTypeMismatch", err)
+	}
+}
+
+func TestStrictTypesReport(t *testing.T) {
+	policies := []Policy{
+		{
+			Name: "Account",
+			Rules: []Rule{
+				{Field: "Zip", Operator: "==", Value: 7},
+				{Field: "Status", Operator: "==", Value: "active"},
+				{Any: []Rule{
+					{Field: "AccountID", Operator: ">=", Value: 1000},
+					{Field: "Logins", Operator: ">", Value: 1},
+				}},
+			},
+		},
+		{
+			Name:  "Roles",
+			Rules: []Rule{{Field: "Roles", Operator: "==", Value: []string{"admin", "editor"}}},
+		},
+		{
+			Name:  "Ranges",
+			Rules: []Rule{{Field: "Zip", Operator: "between", Value: []any{1, 10}}},
+		},
+	}
+
+	other := newStrictAccount()
+	other.Zip = "7"
+
+	changes := StrictTypesReport(policies, []any{newStrictAccount(), "not a struct", other})
+
+	expected := []struct {
+		policy   string
+		field    string
+		resource int
+	}{
+		{"Account", "Zip", 0},
+		{"Account", "AccountID", 0},
+		{"Account", "Zip", 2},
+		{"Account", "AccountID", 2},
+		{"Ranges", "Zip", 0},
+		{"Ranges", "Zip", 2},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("StrictTypesReport() returned %d changes; want %d: %v", len(changes), len(expected), changes)
+	}
+
+	for i, e := range expected {
+		change := changes[i]
+
+		if change.Policy != e.policy || change.Rule.Field != e.field || change.Resource != e.resource {
+			t.Errorf("change %d = %s; want rule %s of policy %q on resource %d", i, change, e.field, e.policy, e.resource)
+		}
+
+		if !change.Lenient.Passed || change.Lenient.Err != nil || !errors.Is(change.Strict.Err, ErrTypeMismatch) {
+			t.Errorf("change %d = %s; want a rule that passes and fails with strict types", i, change)
+		}
+	}
+
+	want := `policy "Account": rule 'Zip == 7' on resource 0 (got 007): pass, with strict types error (cannot compare a string with a number with strict types)`
+	if got := changes[0].String(); got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+
+	if !strings.Contains(changes[1].String(), "rule 'AccountID >= 1000'") {
+		t.Errorf("String() = %q; want the rule within the group", changes[1].String())
+	}
+}
@@ -0,0 +1,144 @@
+package go_policy_enforcer
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/kmesiab/go-policy-enforcer/internal/utils"
+)
+
+// stringOperatorOptions are the options of the operators that compare strings.
+// Values are passed as they are, so that numeric strings are not coerced and
+// []string fields reach the operator, which applies itself to each element.
+var stringOperatorOptions = operatorOptions{prepare: prepareString, exact: true, handlesSlices: true}
+
+// prepareString validates that the value of a string operator rule is a string.
+func prepareString(value any) (any, error) {
+	if _, ok := value.(string); !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a string rule must be a string, got %T", value)
+	}
+
+	return value, nil
+}
+
+// anyString applies test to leftVal when it is a string, or to each element when
+// it is a slice or array of strings, and reports whether test passed for the
+// string or for any element. ok is false when leftVal is neither.
+func anyString(leftVal any, test func(string) bool) (matched, ok bool) {
+	matched, _, ok = testStrings(leftVal, test)
+	return matched, ok
+}
+
+// testStrings applies test to leftVal when it is a string, or to each element
+// when it is a slice or array of strings, and reports whether test passed for
+// any of them and whether it passed for all of them. Strings of any type, such
+// as "type Email string", and values with a text form are accepted, as stringOf
+// describes. ok is false when leftVal, or any element, is not a string.
+func testStrings(leftVal any, test func(string) bool) (anyPassed, allPassed, ok bool) {
+	if left, isString := stringOf(leftVal); isString {
+		passed := test(left)
+		return passed, passed, true
+	}
+
+	v := reflect.ValueOf(leftVal)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false, false, false
+	}
+
+	allPassed = true
+
+	for i := 0; i < v.Len(); i++ {
+		elem, isString := stringOf(utils.DereferencePointer(v.Index(i).Interface()))
+		if !isString {
+			return false, false, false
+		}
+
+		if test(elem) {
+			anyPassed = true
+		} else {
+			allPassed = false
+		}
+	}
+
+	return anyPassed, allPassed, true
+}
+
+// stringPolicyCheckOperator returns an operator that passes when test passes for
+// the left value, or for any element of a []string left value, and the right
+// value. When negate is true it instead passes when test fails for the left
+// value, or for every element. Either way, it fails when the values are not
+// strings.
+func stringPolicyCheckOperator(test func(left, right string) bool, negate bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		right, ok := stringOf(rightVal)
+		if !ok {
+			return false
+		}
+
+		matched, ok := anyString(leftVal, func(left string) bool {
+			return test(left, right)
+		})
+
+		return ok && matched != negate
+	}
+}
+
+// allStringsPolicyCheckOperator returns an operator that passes when test passes
+// for the left value, or for every element of a []string left value, and the
+// right value. Like the negated string operators, it passes for an empty list.
+func allStringsPolicyCheckOperator(test func(left, right string) bool) func(leftVal, rightVal any) bool {
+	return func(leftVal, rightVal any) bool {
+		right, ok := stringOf(rightVal)
+		if !ok {
+			return false
+		}
+
+		_, allPassed, ok := testStrings(leftVal, func(left string) bool {
+			return test(left, right)
+		})
+
+		return ok && allPassed
+	}
+}
+
+// containsPolicyCheckOperator checks if the left string contains the right string.
+var containsPolicyCheckOperator = stringPolicyCheckOperator(strings.Contains, false)
+
+// notContainsPolicyCheckOperator checks if the left string does not contain the right string.
+var notContainsPolicyCheckOperator = stringPolicyCheckOperator(strings.Contains, true)
+
+// startsWithPolicyCheckOperator checks if the left string starts with the right string.
+var startsWithPolicyCheckOperator = stringPolicyCheckOperator(strings.HasPrefix, false)
+
+// notStartsWithPolicyCheckOperator checks if the left string does not start with the right string.
+var notStartsWithPolicyCheckOperator = stringPolicyCheckOperator(strings.HasPrefix, true)
+
+// endsWithPolicyCheckOperator checks if the left string ends with the right string.
+var endsWithPolicyCheckOperator = stringPolicyCheckOperator(strings.HasSuffix, false)
+
+// notEndsWithPolicyCheckOperator checks if the left string does not end with the right string.
+var notEndsWithPolicyCheckOperator = stringPolicyCheckOperator(strings.HasSuffix, true)
+
+// equalsIgnoreCasePolicyCheckOperator checks if the left and right strings are
+// equal under Unicode case folding.
+var equalsIgnoreCasePolicyCheckOperator = stringPolicyCheckOperator(strings.EqualFold, false)
+
+// notEqualsIgnoreCasePolicyCheckOperator checks if the left and right strings are
+// not equal under Unicode case folding.
+var notEqualsIgnoreCasePolicyCheckOperator = stringPolicyCheckOperator(strings.EqualFold, true)
+
+// allContainPolicyCheckOperator checks if the left string, or every element of
+// a []string, contains the right string.
+var allContainPolicyCheckOperator = allStringsPolicyCheckOperator(strings.Contains)
+
+// allStartWithPolicyCheckOperator checks if the left string, or every element
+// of a []string, starts with the right string.
+var allStartWithPolicyCheckOperator = allStringsPolicyCheckOperator(strings.HasPrefix)
+
+// allEndWithPolicyCheckOperator checks if the left string, or every element of
+// a []string, ends with the right string.
+var allEndWithPolicyCheckOperator = allStringsPolicyCheckOperator(strings.HasSuffix)
+
+// allEqualIgnoreCasePolicyCheckOperator checks if the left string, or every
+// element of a []string, equals the right string under Unicode case folding.
+var allEqualIgnoreCasePolicyCheckOperator = allStringsPolicyCheckOperator(strings.EqualFold)
@@ -0,0 +1,189 @@
+package go_policy_enforcer
+
+import (
+	"errors"
+	"testing"
+)
+
+// emailAddress is a named string type, compared as its content.
+type emailAddress string
+
+func TestStringPolicyCheckOperators(t *testing.T) {
+	email := "jane@ourcorp.com"
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"contains", "jane@ourcorp.com", "@our", true},
+		{"contains", "jane@ourcorp.com", "@other", false},
+		{"contains", "jane", "", true},
+		{"not_contains", "jane@ourcorp.com", "@other", true},
+		{"not_contains", "jane@ourcorp.com", "@our", false},
+		{"starts_with", "jane@ourcorp.com", "jane", true},
+		{"starts_with", "jane@ourcorp.com", "JANE", false},
+		{"not_starts_with", "jane@ourcorp.com", "admin", true},
+		{"ends_with", "jane@ourcorp.com", "@ourcorp.com", true},
+		{"ends_with", &email, "@ourcorp.com", true},
+		{"ends_with", "jane@ourcorp.com.evil", "@ourcorp.com", false},
+		{"not_ends_with", "jane@ourcorp.com", "@ourcorp.com", false},
+		{"equals_ignore_case", "Jane@OurCorp.com", "jane@ourcorp.com", true},
+		{"equals_ignore_case", "jane", "janet", false},
+		{"not_equals_ignore_case", "JANE", "jane", false},
+		{"not_equals_ignore_case", "jane", "janet", true},
+		{"starts_with", "0123", "01", true}, // Numeric strings stay strings
+
+		// Values that are not strings never pass
+		{"contains", 12345, "23", false},
+		{"not_contains", 12345, "9", false},
+		{"contains", "12345", 23, false},
+		{"contains", nil, "", false},
+
+		// Slices pass when any element does, negations when every element does
+		{"ends_with", []string{"jane@gmail.com", "jane@ourcorp.com"}, "@ourcorp.com", true},
+		{"ends_with", []string{"jane@gmail.com", "jane@yahoo.com"}, "@ourcorp.com", false},
+		{"not_ends_with", []string{"jane@gmail.com", "jane@ourcorp.com"}, "@ourcorp.com", false},
+		{"not_ends_with", []string{"jane@gmail.com", "jane@yahoo.com"}, "@ourcorp.com", true},
+		{"equals_ignore_case", []any{"Admin", "Editor"}, "admin", true},
+		{"contains", []*string{&email}, "ourcorp", true},
+		{"contains", []string{}, "ourcorp", false},
+		{"not_contains", []string{}, "ourcorp", true},
+		{"contains", []any{"ourcorp", 1}, "ourcorp", false},
+		{"not_contains", []any{"other", 1}, "ourcorp", false},
+
+		// Named string types and values with a text form are strings too
+		{"ends_with", emailAddress("jane@ourcorp.com"), "@ourcorp.com", true},
+		{"not_ends_with", emailAddress("jane@ourcorp.com"), "@ourcorp.com", false},
+		{"contains", []emailAddress{"jane@gmail.com", "jane@ourcorp.com"}, "ourcorp", true},
+		{"starts_with", strictStatus("active"), "act", true},
+		{"equals_ignore_case", logLevel(2), "WARN", true},
+		{"contains", "jane@ourcorp.com", emailAddress("ourcorp"), true},
+
+		// The all_ forms pass when every element does
+		{"all_ends_with", []string{"jane@ourcorp.com", "joe@ourcorp.com"}, "@ourcorp.com", true},
+		{"all_ends_with", []string{"jane@ourcorp.com", "jane@gmail.com"}, "@ourcorp.com", false},
+		{"all_ends_with", "jane@ourcorp.com", "@ourcorp.com", true},
+		{"all_ends_with", []string{}, "@ourcorp.com", true},
+		{"all_contains", []emailAddress{"jane@ourcorp.com"}, "ourcorp", true},
+		{"all_starts_with", []string{"team-a", "team-b"}, "team-", true},
+		{"all_starts_with", []string{"team-a", "contractor-b"}, "team-", false},
+		{"all_equals_ignore_case", []string{"Admin", "ADMIN"}, "admin", true},
+		{"all_equals_ignore_case", []string{"Admin", "Editor"}, "admin", false},
+		{"all_contains", []any{"ourcorp", 1}, "ourcorp", false},
+		{"all_contains", 12345, "23", false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_Evaluate_StringOperators(t *testing.T) {
+	type User struct {
+		Email  string
+		Groups []string
+	}
+
+	policies := []Policy{
+		{
+			Name: "Staff",
+			Rules: []Rule{
+				{Field: "Email", Operator: "ends_with", Value: "@ourcorp.com"},
+				{Field: "Groups", Operator: "not_starts_with", Value: "contractor-"},
+			},
+		},
+	}
+
+	program, err := Compile(policies, User{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		user     User
+		expected bool
+	}{
+		{"staff", User{Email: "jane@ourcorp.com", Groups: []string{"engineering"}}, true},
+		{"no groups", User{Email: "jane@ourcorp.com"}, true},
+		{"contractor", User{Email: "jane@ourcorp.com", Groups: []string{"engineering", "contractor-acme"}}, false},
+		{"other domain", User{Email: "jane@example.com", Groups: []string{"engineering"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := policies[0].Evaluate(tt.user); result != tt.expected {
+				t.Errorf("expected Evaluate to return %v, but got %v", tt.expected, result)
+			}
+
+			if result := program.Enforce(tt.user); result != tt.expected {
+				t.Errorf("expected the compiled policy to return %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPolicy_Evaluate_StringOperatorsNamedTypes(t *testing.T) {
+	type User struct {
+		Email  emailAddress
+		Emails []emailAddress
+	}
+
+	user := User{Email: "jane@ourcorp.com", Emails: []emailAddress{"jane@ourcorp.com", "jane@gmail.com"}}
+
+	tests := []struct {
+		rule     Rule
+		expected bool
+	}{
+		{Rule{Field: "Email", Operator: "==", Value: "jane@ourcorp.com"}, true},
+		{Rule{Field: "Email", Operator: "ends_with", Value: "@ourcorp.com"}, true},
+		{Rule{Field: "Email", Operator: "contains", Value: "@"}, true},
+		{Rule{Field: "Email", Operator: "starts_with", Value: "jane"}, true},
+		{Rule{Field: "Email", Operator: "matches", Value: `@ourcorp\.com$`}, true},
+		{Rule{Field: "Email", Operator: "glob", Value: "jane@*"}, true},
+		{Rule{Field: "Emails", Operator: "ends_with", Value: "@gmail.com"}, true},
+		{Rule{Field: "Emails", Operator: "all_ends_with", Value: "@ourcorp.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule.Operator, func(t *testing.T) {
+			policy := Policy{Name: "Email", Rules: []Rule{tt.rule}}
+
+			if result, err := policy.EvaluateE(user); result != tt.expected || err != nil {
+				t.Errorf("EvaluateE() = %v, %v; want %v, nil", result, err, tt.expected)
+			}
+
+			program, err := Compile([]Policy{policy}, User{})
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			if result := program.Enforce(user); result != tt.expected {
+				t.Errorf("compiled Enforce() = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateE_StringOperatorInvalidValue(t *testing.T) {
+	policy := Policy{Name: "Domain", Rules: []Rule{{Field: "Email", Operator: "ends_with", Value: 42}}}
+
+	_, err := policy.EvaluateE(struct{ Email string }{Email: "jane@ourcorp.com"})
+	if !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule, but got %v", err)
+	}
+
+	if _, err := Compile([]Policy{policy}, struct{ Email string }{}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"time"
+)
+
+// nowKeyword is the rule value that stands for the current time.
+const nowKeyword = "now"
+
+// clockKey is the context key under which evaluation stores the clock set with
+// WithClock.
+type clockKey struct{}
+
+// withClock returns a context that carries clock, or ctx itself when clock is nil.
+func withClock(ctx context.Context, clock func() time.Time) context.Context {
+	if clock == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// now returns the current time from the clock carried by ctx, or time.Now when
+// there is none.
+func now(ctx context.Context) time.Time {
+	if clock, ok := ctx.Value(clockKey{}).(func() time.Time); ok {
+		return clock()
+	}
+
+	return time.Now()
+}
+
+// timeValue is the prepared value of a "before" or "after" rule: either a fixed
+// time, or an offset from the current time.
+type timeValue struct {
+	fixed    time.Time
+	relative bool
+	offset   time.Duration
+}
+
+// resolve returns the time the value stands for, reading the clock from ctx
+// when it is relative.
+func (v timeValue) resolve(ctx context.Context) time.Time {
+	if v.relative {
+		return now(ctx).Add(v.offset)
+	}
+
+	return v.fixed
+}
+
+// toTimeValue converts a rule value to a timeValue. The value can be a
+// time.Time or an RFC 3339 string, a time.Duration or a duration string such as
+// "-24h", which are relative to the current time, or "now".
+func toTimeValue(value any) (timeValue, bool) {
+	switch v := value.(type) {
+	case timeValue:
+		return v, true
+	case time.Time:
+		return timeValue{fixed: v}, true
+	case time.Duration:
+		return timeValue{relative: true, offset: v}, true
+	case string:
+		if v == nowKeyword {
+			return timeValue{relative: true}, true
+		}
+
+		if t, ok := toTime(v); ok {
+			return timeValue{fixed: t}, true
+		}
+
+		if d, ok := toDuration(v); ok {
+			return timeValue{relative: true, offset: d}, true
+		}
+	}
+
+	return timeValue{}, false
+}
+
+// prepareTimeValue validates the value of a "before" or "after" rule.
+func prepareTimeValue(value any) (any, error) {
+	v, ok := toTimeValue(value)
+	if !ok {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a time rule must be an RFC 3339 time, a duration or %q, got %v", nowKeyword, value)
+	}
+
+	return v, nil
+}
+
+// prepareWithin validates the value of a "within" rule, which must be a
+// positive duration.
+func prepareWithin(value any) (any, error) {
+	d, ok := toDuration(value)
+	if !ok || d <= 0 {
+		return nil, newEvaluationError(ErrInvalidRule, "the value of a within rule must be a positive duration, got %v", value)
+	}
+
+	return d, nil
+}
+
+// timePolicyCheckOperator returns an operator that passes when the left value,
+// a time.Time or an RFC 3339 string, and the time the right value stands for
+// satisfy test.
+func timePolicyCheckOperator(test func(t, other time.Time) bool) ContextPolicyCheckOperator {
+	return func(ctx context.Context, leftVal, rightVal any) bool {
+		t, ok := toTime(leftVal)
+		if !ok {
+			return false
+		}
+
+		other, ok := toTimeValue(rightVal)
+
+		return ok && test(t, other.resolve(ctx))
+	}
+}
+
+// beforePolicyCheckOperator checks if the left time is before the right time,
+// such as "2025-01-01T00:00:00Z", or "-24h" for 24 hours before now.
+var beforePolicyCheckOperator = timePolicyCheckOperator(time.Time.Before)
+
+// afterPolicyCheckOperator checks if the left time is after the right time,
+// such as "2025-01-01T00:00:00Z", or "now".
+var afterPolicyCheckOperator = timePolicyCheckOperator(time.Time.After)
+
+// withinPolicyCheckOperator checks if the left time lies within the duration in
+// the right value before now, so "24h" passes for times in the last 24 hours.
+// Times in the future do not pass.
+var withinPolicyCheckOperator = func(ctx context.Context, leftVal, rightVal any) bool {
+	t, ok := toTime(leftVal)
+	if !ok {
+		return false
+	}
+
+	d, ok := toDuration(rightVal)
+	if !ok || d <= 0 {
+		return false
+	}
+
+	current := now(ctx)
+
+	return !t.Before(current.Add(-d)) && !t.After(current)
+}
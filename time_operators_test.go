@@ -0,0 +1,187 @@
+package go_policy_enforcer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testClock() time.Time {
+	return testNow
+}
+
+func TestTimePolicyCheckOperators(t *testing.T) {
+	ctx := withClock(context.Background(), testClock)
+	paris := time.FixedZone("CEST", 2*60*60)
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"before", testNow.Add(-time.Hour), "now", true},
+		{"before", testNow.Add(time.Hour), "now", false},
+		{"before", testNow, "2024-06-01T13:00:00Z", true},
+		{"before", testNow, "2024-06-01T13:00:00+02:00", false}, // 11:00 UTC
+		{"before", testNow.In(paris), testNow.Add(time.Minute), true},
+		{"before", "2024-05-31T00:00:00Z", "-24h", true},
+		{"before", testNow.Add(-time.Hour), time.Duration(-2 * time.Hour), false},
+		{"after", testNow.Add(time.Hour), "now", true},
+		{"after", testNow.Add(time.Hour), "90m", false},
+		{"after", testNow, "2024-01-01T00:00:00Z", true},
+		{"after", "not a time", "now", false},
+		{"after", testNow, "tomorrow", false},
+		{"after", 1717243200, "now", false},
+		{"within", testNow.Add(-23 * time.Hour), "24h", true},
+		{"within", testNow.Add(-25 * time.Hour), "24h", false},
+		{"within", testNow.Add(-24 * time.Hour), 24 * time.Hour, true},
+		{"within", testNow.Add(time.Minute), "24h", false},
+		{"within", "2024-06-01T11:30:00Z", "1h", true},
+		{"within", testNow, "-1h", false},
+	}
+
+	for _, tt := range tests {
+		op, err := DefaultOperatorRegistry.lookup(tt.operator)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := applyPolicyCheckOperator(ctx, tt.operator, op, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestComparisonOperators_Times(t *testing.T) {
+	utc := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tokyo := utc.In(time.FixedZone("JST", 9*60*60))
+
+	tests := []struct {
+		operator string
+		leftVal  any
+		rightVal any
+		expected bool
+	}{
+		{"==", tokyo, utc, true},
+		{"!=", tokyo, utc, false},
+		{"==", utc, "2024-06-01T21:00:00+09:00", true},
+		{">", tokyo, utc.Add(-time.Second), true},
+		{"<", tokyo, utc.Add(-time.Second), false},
+		{">=", utc, "2024-06-01T12:00:00Z", true},
+		{"<=", utc, "2024-06-01T11:59:59Z", false},
+		{">", 90 * time.Minute, "1h", true},
+		{"<", 90 * time.Minute, 2 * time.Hour, true},
+		{"==", time.Hour, "60m", true},
+		{">", utc, "yesterday", false},
+		{"==", utc, 5, false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluatePolicyCheckOperator(tt.operator, tt.leftVal, tt.rightVal)
+		if err != nil {
+			t.Errorf("unexpected error for %v %s %v: %v", tt.leftVal, tt.operator, tt.rightVal, err)
+			continue
+		}
+
+		if result != tt.expected {
+			t.Errorf("%v %s %v = %v; want %v", tt.leftVal, tt.operator, tt.rightVal, result, tt.expected)
+		}
+	}
+}
+
+func TestPolicyEnforcer_WithClock(t *testing.T) {
+	type Session struct {
+		LastLogin time.Time
+		ExpiresAt string
+	}
+
+	policies := []Policy{
+		{
+			Name: "ActiveSession",
+			Rules: []Rule{
+				{Field: "LastLogin", Operator: "within", Value: "24h"},
+				{Field: "ExpiresAt", Operator: "after", Value: "now"},
+			},
+		},
+	}
+
+	program, err := Compile(policies, Session{}, WithClock(testClock))
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	enforcers := map[string]PolicyEnforcerInterface{
+		"enforcer": NewPolicyEnforcer(&policies, WithClock(testClock)),
+		"program":  program,
+	}
+
+	tests := []struct {
+		name     string
+		session  Session
+		expected bool
+	}{
+		{"active", Session{LastLogin: testNow.Add(-time.Hour), ExpiresAt: "2024-06-01T13:00:00Z"}, true},
+		{"stale login", Session{LastLogin: testNow.Add(-48 * time.Hour), ExpiresAt: "2024-06-01T13:00:00Z"}, false},
+		{"expired", Session{LastLogin: testNow.Add(-time.Hour), ExpiresAt: "2024-06-01T11:00:00Z"}, false},
+	}
+
+	for name, enforcer := range enforcers {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				if result := enforcer.Enforce(tt.session); result != tt.expected {
+					t.Errorf("expected Enforce to return %v, but got %v", tt.expected, result)
+				}
+
+				matched := len(enforcer.Match(tt.session)) == 1
+				if matched != tt.expected {
+					t.Errorf("expected Match to match %v, but got %v", tt.expected, matched)
+				}
+			})
+		}
+	}
+
+	// Without a clock, the current time is used
+	session := Session{LastLogin: testNow.Add(-time.Hour), ExpiresAt: "2024-06-01T13:00:00Z"}
+	if NewPolicyEnforcer(&policies).Enforce(session) {
+		t.Errorf("expected the session to have expired by the current time")
+	}
+}
+
+func TestPolicy_EvaluateE_InvalidTimeRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    any
+	}{
+		{"invalid time", "before", "2024-13-01"},
+		{"number", "after", 1717243200},
+		{"invalid duration", "within", "a day"},
+		{"negative duration", "within", "-24h"},
+		{"time instead of duration", "within", "2024-06-01T12:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Name: "Time", Rules: []Rule{{Field: "At", Operator: tt.operator, Value: tt.value}}}
+
+			_, err := policy.EvaluateE(struct{ At time.Time }{At: testNow})
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected ErrInvalidRule, but got %v", err)
+			}
+
+			if _, err := Compile([]Policy{policy}, struct{ At time.Time }{}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected Compile to return ErrInvalidRule, but got %v", err)
+			}
+		})
+	}
+}